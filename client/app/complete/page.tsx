@@ -22,7 +22,7 @@ export default function CompletePage() {
 
     if (food) {
       setLoading(true);
-      getFoodInfo(food)
+      getFoodInfo(food, localStorage.getItem("session_id"))
         .then(setFoodInfo)
         .catch(() => {})
         .finally(() => setLoading(false));
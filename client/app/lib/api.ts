@@ -1,12 +1,17 @@
 const API_BASE = process.env.NEXT_PUBLIC_API_URL || "http://localhost:8000";
 
-export async function createSession(): Promise<{ session_id: string }> {
-  const res = await fetch(`${API_BASE}/session`, { method: "POST" });
+export async function createSession(catalog?: string): Promise<{ session_id: string }> {
+  const res = await fetch(`${API_BASE}/session`, {
+    method: "POST",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify(catalog ? { catalog } : {}),
+  });
   if (!res.ok) throw new Error("Failed to create session");
   return res.json();
 }
 
 export async function getRecommendation(sessionId: string): Promise<{
+  id?: string;
   name: string;
   description?: string;
 }> {
@@ -17,7 +22,7 @@ export async function getRecommendation(sessionId: string): Promise<{
 
 export async function sendSwipe(
   sessionId: string,
-  foodName: string,
+  food: { id?: string; name: string },
   action: "left" | "right" | "super"
 ): Promise<void> {
   const res = await fetch(`${API_BASE}/swipe`, {
@@ -25,19 +30,22 @@ export async function sendSwipe(
     headers: { "Content-Type": "application/json" },
     body: JSON.stringify({
       session_id: sessionId,
-      food_name: foodName,
+      food_id: food.id,
+      food_name: food.name,
       action,
     }),
   });
   if (!res.ok) throw new Error("Failed to send swipe");
 }
 
-export async function getFoodInfo(foodName: string): Promise<{
+export async function getFoodInfo(foodName: string, sessionId?: string | null): Promise<{
   description: string;
   nutrients: string;
   similar_foods: string[];
 }> {
-  const res = await fetch(`${API_BASE}/food-info?food_name=${encodeURIComponent(foodName)}`);
+  let url = `${API_BASE}/food-info?food_name=${encodeURIComponent(foodName)}`;
+  if (sessionId) url += `&session_id=${encodeURIComponent(sessionId)}`;
+  const res = await fetch(url);
   if (!res.ok) throw new Error("Failed to get food info");
   return res.json();
 }
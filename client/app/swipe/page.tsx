@@ -7,6 +7,7 @@ import { createSession, getRecommendation, sendSwipe } from "../lib/api";
 export default function SwipePage() {
   const router = useRouter();
   const [food, setFood] = useState<{
+    id?: string;
     name: string;
     description?: string;
   } | null>(null);
@@ -29,10 +30,18 @@ export default function SwipePage() {
       setLoading(true);
       setError(null);
 
+      // QR codes link to /swipe?catalog=<id> to start on a restaurant's own menu
+      const param = new URLSearchParams(window.location.search).get("catalog");
+      if (param !== null && param !== localStorage.getItem("catalog")) {
+        localStorage.setItem("catalog", param);
+        localStorage.removeItem("session_id");
+      }
+      const catalog = localStorage.getItem("catalog") || undefined;
+
       let sessionId = localStorage.getItem("session_id");
 
       if (!sessionId) {
-        const { session_id } = await createSession();
+        const { session_id } = await createSession(catalog);
         sessionId = session_id;
         localStorage.setItem("session_id", sessionId);
       }
@@ -42,7 +51,7 @@ export default function SwipePage() {
         setFood(recommendation);
       } catch {
         localStorage.removeItem("session_id");
-        const { session_id } = await createSession();
+        const { session_id } = await createSession(catalog);
         localStorage.setItem("session_id", session_id);
         const recommendation = await getRecommendation(session_id);
         setFood(recommendation);
@@ -68,7 +77,7 @@ export default function SwipePage() {
       setLoading(true);
       setError(null);
 
-      await sendSwipe(sessionId, food.name, action);
+      await sendSwipe(sessionId, food, action);
 
       if (action === "super") {
         localStorage.setItem("selected_food", food.name);
@@ -0,0 +1,174 @@
+package catalog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"server2/models"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// catalog file formats
+const (
+	FormatJSON   = "json"   // canonical foods.json, an array of models.Food
+	FormatCSV    = "csv"    // one food per row, header names match the json fields
+	FormatYAML   = "yaml"   // same fields as json
+	FormatJSONLD = "jsonld" // schema.org Menu / MenuItem
+)
+
+// turns a catalog file into foods
+type Loader interface {
+	Load(r io.Reader) ([]models.Food, error)
+}
+
+var loaders = map[string]Loader{
+	FormatJSON:   jsonLoader{},
+	FormatCSV:    csvLoader{},
+	FormatYAML:   yamlLoader{},
+	FormatJSONLD: jsonLDLoader{},
+}
+
+// file extensions for each format
+var extensions = map[string]string{
+	".json":   FormatJSON,
+	".csv":    FormatCSV,
+	".yaml":   FormatYAML,
+	".yml":    FormatYAML,
+	".jsonld": FormatJSONLD,
+}
+
+// adds or replaces the loader for a format
+func Register(format string, loader Loader) {
+	loaders[format] = loader
+}
+
+// registered format names, sorted
+func Formats() []string {
+	names := make([]string, 0, len(loaders))
+	for name := range loaders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// picks the format from the file extension
+func DetectFormat(path string) (string, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if format, ok := extensions[ext]; ok {
+		return format, nil
+	}
+	return "", fmt.Errorf("can't tell the catalog format of %s, pass one of %s", path, strings.Join(Formats(), ", "))
+}
+
+// reads a catalog file, format "" detects it from the extension
+func ReadFile(path, format string) ([]models.Food, error) {
+	if format == "" {
+		var err error
+		if format, err = DetectFormat(path); err != nil {
+			return nil, err
+		}
+	}
+	loader, ok := loaders[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown catalog format %q (known: %s)", format, strings.Join(Formats(), ", "))
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read foods file: %w", err)
+	}
+	defer f.Close()
+
+	foods, err := loader.Load(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s foods: %w", format, err)
+	}
+	return foods, nil
+}
+
+// encodes foods as canonical foods.json
+func MarshalJSON(foods []models.Food) ([]byte, error) {
+	data, err := json.MarshalIndent(foods, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// gives foods without an ID one derived from their name, "Masala Dosa"
+// becomes "masala-dosa", so IDs stay put when rows are reordered or added.
+// used by the spreadsheet and menu formats, foods.json must carry its own IDs.
+// a derived ID that is already taken is an error, the food needs an explicit id.
+func assignIDs(foods []models.Food) error {
+	taken := make(map[string]string, len(foods))
+	for _, food := range foods {
+		if id := strings.TrimSpace(food.ID); id != "" {
+			taken[id] = food.Name
+		}
+	}
+	for i := range foods {
+		if strings.TrimSpace(foods[i].ID) != "" {
+			continue
+		}
+		id := nameID(foods[i].Name)
+		if id == "" {
+			continue // no name either, validation reports it
+		}
+		if other, ok := taken[id]; ok {
+			return fmt.Errorf("food %q would get id %q, already used by %q, give it an explicit id", foods[i].Name, id, other)
+		}
+		taken[id] = foods[i].Name
+		foods[i].ID = id
+	}
+	return nil
+}
+
+// lowercase letters and digits of the normalized name, joined by dashes
+func nameID(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range models.NormalizeName(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return b.String()
+}
+
+// true if json data is a schema.org document (an object) rather than a
+// foods.json array
+func IsJSONLD(data []byte) bool {
+	trimmed := bytes.TrimSpace(data)
+	return len(trimmed) > 0 && trimmed[0] == '{'
+}
+
+// foods.json, or a schema.org document saved with a .json extension
+type jsonLoader struct{}
+
+func (jsonLoader) Load(r io.Reader) ([]models.Food, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if IsJSONLD(data) {
+		return jsonLDLoader{}.Load(bytes.NewReader(data))
+	}
+
+	var foods []models.Food
+	if err := json.Unmarshal(data, &foods); err != nil {
+		return nil, err
+	}
+	return foods, nil
+}
@@ -0,0 +1,184 @@
+package catalog
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"server2/models"
+	"strings"
+	"testing"
+)
+
+func TestDetectFormat(t *testing.T) {
+	tests := map[string]string{
+		"data/foods.json": FormatJSON,
+		"menu.CSV":        FormatCSV,
+		"menu.yml":        FormatYAML,
+		"menu.yaml":       FormatYAML,
+		"menu.jsonld":     FormatJSONLD,
+	}
+	for path, want := range tests {
+		if got, err := DetectFormat(path); err != nil || got != want {
+			t.Errorf("DetectFormat(%q) = %q, %v, want %q", path, got, err, want)
+		}
+	}
+	if _, err := DetectFormat("menu.xlsx"); err == nil {
+		t.Error("Unknown extensions should be an error")
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	foods, err := ReadFile("testdata/menu.csv", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(foods) != 2 {
+		t.Fatalf("Expected 2 foods (blank rows skipped), got %d", len(foods))
+	}
+
+	dosa := foods[0]
+	if dosa.ID != "masala-dosa" || dosa.Name != "Masala Dosa" || !dosa.Vegetarian || dosa.Vegan || dosa.SpiceLevel != 1 {
+		t.Errorf("Unexpected first row: %+v", dosa)
+	}
+	if !reflect.DeepEqual(dosa.Tags, []string{"crispy", "savory"}) {
+		t.Errorf("Tags = %v", dosa.Tags)
+	}
+	if dosa.Nutrients == nil || dosa.Nutrients.Calories != 380 || dosa.Nutrients.ProteinG != 8 {
+		t.Errorf("Nutrients = %+v", dosa.Nutrients)
+	}
+
+	tiramisu := foods[1]
+	if tiramisu.ID != "tiramisu" || !reflect.DeepEqual(tiramisu.Allergens, []string{"dairy", "egg", "gluten"}) ||
+		!reflect.DeepEqual(tiramisu.Tags, []string{"sweet", "coffee"}) || tiramisu.Nutrients != nil {
+		t.Errorf("Unexpected second row: %+v", tiramisu)
+	}
+	if err := models.ValidateFoods(foods); err != nil {
+		t.Errorf("Imported foods should be valid: %v", err)
+	}
+}
+
+func TestReadCSVErrors(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"no name column", "title,description\nDal,Lentils\n", "missing a name column"},
+		{"bad number", "name,description,spice_level\nDal,Lentils,\nSoup,Hot,very\n", `line 3: spice_level: invalid value "very"`},
+		{"bad bool", "name,vegan\nDal,maybe\n", `vegan: invalid value "maybe"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "menu.csv")
+			os.WriteFile(path, []byte(tt.content), 0o644)
+			_, err := ReadFile(path, "")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %v, want error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssignIDs(t *testing.T) {
+	foods := []models.Food{{Name: "  Crème  Brûlée!"}, {ID: "7", Name: "Dal"}, {Name: "Pad Thai (v2)"}}
+	if err := assignIDs(foods); err != nil {
+		t.Fatal(err)
+	}
+	if foods[0].ID != "crème-brûlée" || foods[1].ID != "7" || foods[2].ID != "pad-thai-v2" {
+		t.Errorf("Unexpected ids %q, %q, %q", foods[0].ID, foods[1].ID, foods[2].ID)
+	}
+
+	// the same food keeps its id when rows move around
+	reordered := []models.Food{{Name: "Pad Thai (v2)"}, {Name: "Soup"}, {Name: "Crème Brûlée"}}
+	if err := assignIDs(reordered); err != nil || reordered[0].ID != "pad-thai-v2" || reordered[2].ID != "crème-brûlée" {
+		t.Errorf("Ids should not depend on row order, got %q, %q (%v)", reordered[0].ID, reordered[2].ID, err)
+	}
+
+	for _, clash := range [][]models.Food{
+		{{Name: "Pad Thai"}, {Name: "pad  thai"}},
+		{{ID: "dal", Name: "Yellow Dal"}, {Name: "Dal"}},
+	} {
+		if err := assignIDs(clash); err == nil || !strings.Contains(err.Error(), "explicit id") {
+			t.Errorf("Colliding ids should be an error, got %v", err)
+		}
+	}
+}
+
+func TestReadYAML(t *testing.T) {
+	foods, err := ReadFile("testdata/menu.yaml", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(foods) != 2 {
+		t.Fatalf("Expected 2 foods, got %d", len(foods))
+	}
+	if foods[0].ID != "10" || foods[0].Nutrients == nil || foods[0].Nutrients.Calories != 450 {
+		t.Errorf("Unexpected first food: %+v", foods[0])
+	}
+	if foods[1].ID != "spring-rolls" {
+		t.Errorf("Food without id should get one from its name, got %q", foods[1].ID)
+	}
+}
+
+func TestReadJSONLD(t *testing.T) {
+	foods, err := ReadFile("testdata/menu.jsonld", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(foods) != 2 {
+		t.Fatalf("Expected 2 menu items, got %d", len(foods))
+	}
+
+	guac := foods[0]
+	if guac.ID != "guac" || guac.Course != "starter" || guac.Cuisine != "Mexican" {
+		t.Errorf("Unexpected guacamole: %+v", guac)
+	}
+	if !guac.Vegan || !guac.Vegetarian || !guac.GlutenFree {
+		t.Errorf("Diets not mapped: %+v", guac)
+	}
+	if guac.ImageURL != "https://cafe42.example/guac.jpg" || guac.Nutrients.Calories != 230 || guac.Nutrients.FatG != 20 {
+		t.Errorf("Image or nutrition not mapped: %+v", guac)
+	}
+
+	pastor := foods[1]
+	if pastor.ID != "al-pastor" || pastor.Course != "" || !reflect.DeepEqual(pastor.Tags, []string{"tacos", "pork", "street food"}) {
+		t.Errorf("Unexpected al pastor: %+v", pastor)
+	}
+	if err := models.ValidateFoods(foods); err != nil {
+		t.Errorf("Imported foods should be valid: %v", err)
+	}
+}
+
+func TestJSONFileWithMenu(t *testing.T) {
+	data, _ := os.ReadFile("testdata/menu.jsonld")
+	path := filepath.Join(t.TempDir(), "menu.json")
+	os.WriteFile(path, data, 0o644)
+
+	foods, err := ReadFile(path, "")
+	if err != nil || len(foods) != 2 {
+		t.Errorf("A schema.org menu saved as .json should still load, got %d foods, %v", len(foods), err)
+	}
+}
+
+func TestMarshalJSONRoundTrip(t *testing.T) {
+	foods, err := ReadFile("testdata/menu.yaml", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := MarshalJSON(foods)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "foods.json")
+	os.WriteFile(path, data, 0o644)
+	again, err := ReadFile(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(foods, again) {
+		t.Errorf("Round trip changed the catalog:\n%+v\n%+v", foods, again)
+	}
+}
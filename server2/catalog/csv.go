@@ -0,0 +1,171 @@
+package catalog
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"server2/models"
+	"strconv"
+	"strings"
+)
+
+// spreadsheet export with a header row. columns are matched by name
+// (same names as foods.json, plus calories/protein_g/... for nutrients),
+// unknown columns are ignored. list columns are separated by ";" or "|".
+type csvLoader struct{}
+
+func (csvLoader) Load(r io.Reader) ([]models.Food, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	if _, ok := columns["name"]; !ok {
+		return nil, fmt.Errorf("missing a name column")
+	}
+
+	var foods []models.Food
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		row := csvRow{columns: columns, record: record}
+		if row.empty() {
+			continue
+		}
+		food, err := row.food()
+		if err != nil {
+			line, _ := reader.FieldPos(0)
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		foods = append(foods, food)
+	}
+
+	if err := assignIDs(foods); err != nil {
+		return nil, err
+	}
+	return foods, nil
+}
+
+// one spreadsheet row, read by column name
+type csvRow struct {
+	columns map[string]int
+	record  []string
+	err     error
+}
+
+func (r *csvRow) food() (models.Food, error) {
+	food := models.Food{
+		ID:          r.str("id"),
+		Name:        r.str("name"),
+		Aliases:     r.list("aliases"),
+		Description: r.str("description"),
+		Cuisine:     r.str("cuisine"),
+		Course:      strings.ToLower(r.str("course")),
+		Tags:        r.list("tags"),
+		Vegetarian:  r.bool("vegetarian"),
+		Vegan:       r.bool("vegan"),
+		GlutenFree:  r.bool("gluten_free"),
+		Halal:       r.bool("halal"),
+		Allergens:   r.list("allergens"),
+		SpiceLevel:  r.int("spice_level"),
+		PriceRange:  r.int("price_range"),
+		PrepTime:    r.int("prep_time_minutes"),
+		ImageURL:    r.str("image_url"),
+	}
+	if r.str("calories") != "" {
+		food.Nutrients = &models.Nutrients{
+			Calories: r.int("calories"),
+			ProteinG: r.float("protein_g"),
+			CarbsG:   r.float("carbs_g"),
+			FatG:     r.float("fat_g"),
+			FiberG:   r.float("fiber_g"),
+		}
+	}
+	return food, r.err
+}
+
+func (r *csvRow) empty() bool {
+	for _, v := range r.record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *csvRow) str(column string) string {
+	i, ok := r.columns[column]
+	if !ok || i >= len(r.record) {
+		return ""
+	}
+	return strings.TrimSpace(r.record[i])
+}
+
+func (r *csvRow) list(column string) []string {
+	var items []string
+	for _, item := range strings.FieldsFunc(r.str(column), func(c rune) bool { return c == ';' || c == '|' }) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func (r *csvRow) bool(column string) bool {
+	switch v := strings.ToLower(r.str(column)); v {
+	case "", "false", "no", "n", "0":
+		return false
+	case "true", "yes", "y", "1", "x":
+		return true
+	default:
+		r.fail(column, v)
+		return false
+	}
+}
+
+func (r *csvRow) int(column string) int {
+	v := r.str(column)
+	if v == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		r.fail(column, v)
+	}
+	return n
+}
+
+func (r *csvRow) float(column string) float64 {
+	v := r.str(column)
+	if v == "" {
+		return 0
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		r.fail(column, v)
+	}
+	return f
+}
+
+// keeps the first bad value, so the row reports one clear error
+func (r *csvRow) fail(column, value string) {
+	if r.err == nil {
+		r.err = fmt.Errorf("%s: invalid value %q", column, value)
+	}
+}
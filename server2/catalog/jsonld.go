@@ -0,0 +1,217 @@
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"server2/models"
+	"strconv"
+	"strings"
+)
+
+// schema.org menus as published by restaurant sites: a Menu (or a Restaurant with
+// hasMenu, or an @graph holding either) with MenuSections and MenuItems
+type jsonLDLoader struct{}
+
+func (jsonLDLoader) Load(r io.Reader) ([]models.Food, error) {
+	var doc interface{}
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	var foods []models.Food
+	walkMenu(doc, menuContext{}, &foods)
+	if len(foods) == 0 {
+		return nil, fmt.Errorf("no schema.org MenuItem found")
+	}
+
+	if err := assignIDs(foods); err != nil {
+		return nil, err
+	}
+	return foods, nil
+}
+
+// what a menu item inherits from the sections and menu around it
+type menuContext struct {
+	cuisine string
+	course  string
+	section string
+}
+
+// finds MenuItems anywhere under node, keeping track of the enclosing section
+func walkMenu(node interface{}, ctx menuContext, foods *[]models.Food) {
+	switch v := node.(type) {
+	case []interface{}:
+		for _, item := range v {
+			walkMenu(item, ctx, foods)
+		}
+	case map[string]interface{}:
+		if cuisine := ldString(v["servesCuisine"]); cuisine != "" {
+			ctx.cuisine = cuisine
+		}
+		if ldIsType(v, "MenuItem") {
+			*foods = append(*foods, menuItem(v, ctx))
+			return
+		}
+		if ldIsType(v, "MenuSection") {
+			ctx.section = ldString(v["name"])
+			if course := courseFor(ctx.section); course != "" {
+				ctx.course = course
+			}
+		}
+		for _, key := range []string{"@graph", "hasMenu", "hasMenuSection", "hasMenuItem", "mainEntity"} {
+			if child, ok := v[key]; ok {
+				walkMenu(child, ctx, foods)
+			}
+		}
+	}
+}
+
+func menuItem(v map[string]interface{}, ctx menuContext) models.Food {
+	food := models.Food{
+		ID:          ldID(v),
+		Name:        ldString(v["name"]),
+		Description: ldString(v["description"]),
+		Cuisine:     ctx.cuisine,
+		Course:      ctx.course,
+		ImageURL:    ldURL(v["image"]),
+	}
+	if ctx.section != "" && ctx.course == "" {
+		food.Tags = append(food.Tags, strings.ToLower(ctx.section))
+	}
+	for _, keyword := range strings.Split(ldString(v["keywords"]), ",") {
+		if keyword = strings.TrimSpace(keyword); keyword != "" {
+			food.Tags = append(food.Tags, keyword)
+		}
+	}
+
+	for _, diet := range ldList(v["suitableForDiet"]) {
+		name := ldString(diet)
+		name = name[strings.LastIndex(name, "/")+1:]
+		switch name {
+		case "VeganDiet":
+			food.Vegan, food.Vegetarian = true, true
+		case "VegetarianDiet":
+			food.Vegetarian = true
+		case "GlutenFreeDiet":
+			food.GlutenFree = true
+		case "HalalDiet":
+			food.Halal = true
+		}
+	}
+
+	if n, ok := v["nutrition"].(map[string]interface{}); ok {
+		food.Nutrients = &models.Nutrients{
+			Calories: int(ldNumber(n["calories"])),
+			ProteinG: ldNumber(n["proteinContent"]),
+			CarbsG:   ldNumber(n["carbohydrateContent"]),
+			FatG:     ldNumber(n["fatContent"]),
+			FiberG:   ldNumber(n["fiberContent"]),
+		}
+	}
+	return food
+}
+
+// maps common menu section names onto models.Courses
+func courseFor(section string) string {
+	name := strings.ToLower(strings.TrimSpace(section))
+	switch name {
+	case "appetizer", "appetizers", "starters", "small plates":
+		return "starter"
+	case "mains", "main course", "main courses", "entrees", "entrées":
+		return "main"
+	case "sides", "side dishes":
+		return "side"
+	case "desserts", "sweets":
+		return "dessert"
+	case "drinks", "beverages":
+		return "drink"
+	case "snacks":
+		return "snack"
+	}
+	for _, course := range models.Courses {
+		if name == course {
+			return course
+		}
+	}
+	return ""
+}
+
+// true if the node's @type is (or includes) typ
+func ldIsType(v map[string]interface{}, typ string) bool {
+	for _, t := range ldList(v["@type"]) {
+		if name := ldString(t); name == typ || strings.HasSuffix(name, "/"+typ) {
+			return true
+		}
+	}
+	return false
+}
+
+// identifier, or the last segment of @id
+func ldID(v map[string]interface{}) string {
+	if id := ldString(v["identifier"]); id != "" {
+		return id
+	}
+	id := strings.TrimRight(ldString(v["@id"]), "/")
+	if i := strings.LastIndexAny(id, "/#"); i >= 0 {
+		id = id[i+1:]
+	}
+	return id
+}
+
+// values may be single or arrays
+func ldList(v interface{}) []interface{} {
+	if list, ok := v.([]interface{}); ok {
+		return list
+	}
+	if v == nil {
+		return nil
+	}
+	return []interface{}{v}
+}
+
+// a string, a number, or the first of a list, or an object's @id/@value/name
+func ldString(v interface{}) string {
+	switch s := v.(type) {
+	case string:
+		return strings.TrimSpace(s)
+	case float64:
+		return strconv.FormatFloat(s, 'f', -1, 64)
+	case []interface{}:
+		if len(s) > 0 {
+			return ldString(s[0])
+		}
+	case map[string]interface{}:
+		for _, key := range []string{"@value", "@id", "name"} {
+			if str := ldString(s[key]); str != "" {
+				return str
+			}
+		}
+	}
+	return ""
+}
+
+// image as a URL string or an ImageObject
+func ldURL(v interface{}) string {
+	if list := ldList(v); len(list) > 0 {
+		if obj, ok := list[0].(map[string]interface{}); ok {
+			return ldString(obj["url"])
+		}
+		return ldString(list[0])
+	}
+	return ""
+}
+
+// leading number of a quantity like "490 calories" or "32 g"
+func ldNumber(v interface{}) float64 {
+	if f, ok := v.(float64); ok {
+		return f
+	}
+	s := ldString(v)
+	end := 0
+	for end < len(s) && (s[end] == '.' || (s[end] >= '0' && s[end] <= '9')) {
+		end++
+	}
+	f, _ := strconv.ParseFloat(s[:end], 64)
+	return f
+}
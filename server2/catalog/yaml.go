@@ -0,0 +1,40 @@
+package catalog
+
+import (
+	"encoding/json"
+	"io"
+	"server2/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+// a list of foods, or a mapping with a "foods" list, using the foods.json field names
+type yamlLoader struct{}
+
+func (yamlLoader) Load(r io.Reader) ([]models.Food, error) {
+	var doc interface{}
+	if err := yaml.NewDecoder(r).Decode(&doc); err != nil {
+		if err == io.EOF {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if m, ok := doc.(map[string]interface{}); ok {
+		doc = m["foods"]
+	}
+
+	// round trip through json so the json field tags on models.Food apply
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var foods []models.Food
+	if err := json.Unmarshal(data, &foods); err != nil {
+		return nil, err
+	}
+
+	if err := assignIDs(foods); err != nil {
+		return nil, err
+	}
+	return foods, nil
+}
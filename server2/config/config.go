@@ -0,0 +1,192 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// embedding providers
+const (
+	ProviderOpenAI  = "openai"
+	ProviderOllama  = "ollama"
+	ProviderTEI     = "tei"
+	ProviderOffline = "offline"
+)
+
+// deployment settings read from the environment
+type Config struct {
+	Port               string
+	DataPath           string
+	DataFormat         string // catalog format, empty picks it from the file extension
+	CatalogsDir        string // extra catalogs, one file per catalog named <id>.<ext>
+	EmbeddingProvider  string
+	OfflineDimension   int
+	WatchInterval      time.Duration // how often to poll the catalog file for changes, 0 disables
+	AdminToken         string        // bearer token for /admin, empty disables the admin api
+	AuditLogPath       string
+	DuplicateThreshold float64 // cosine similarity at which two foods are reported as near-duplicates
+	SemanticWeight     float64 // weight of embedding similarity in search and text-seeded sessions
+	LexicalWeight      float64 // weight of BM25 keyword matches in the same
+	Strategy           string  // default recommendation strategy
+	Exploration        string  // deprecated EXPLORATION, only read when RECOMMENDER_STRATEGY is unset
+	ExplorationLambda  float64
+	ExplorationWindow  int
+	ExplorationEpsilon float64
+	ExplorationTemp    float64
+	ColdStartClusters  int     // clusters the first cards are drawn from, 0 shows foods in catalog order
+	IntentAlpha        float64 // intent = alpha·query + beta·liked centroid − gamma·disliked centroid
+	IntentBeta         float64
+	IntentGamma        float64
+	LeftSwipeWeight    float64
+	RightSwipeWeight   float64
+	SuperSwipeWeight   float64
+	RecencyDecay       float64 // factor older swipes fade by on every new swipe, 1 disables
+	RecencyWindow      int     // swipes that count toward the intent, 0 counts all
+
+	// per-strategy overrides, by lowercase strategy name,
+	// from RECENCY_DECAY_<STRATEGY> and RECENCY_WINDOW_<STRATEGY>
+	StrategyRecencyDecay  map[string]float64
+	StrategyRecencyWindow map[string]int
+}
+
+// reads the config from env vars (and .env if present)
+func Load() *Config {
+	_ = godotenv.Load()
+
+	cfg := &Config{
+		Port:               getEnv("PORT", "8000"),
+		DataPath:           getEnv("FOODS_PATH", "data/foods.json"),
+		DataFormat:         os.Getenv("FOODS_FORMAT"),
+		CatalogsDir:        os.Getenv("CATALOGS_DIR"),
+		EmbeddingProvider:  os.Getenv("EMBEDDING_PROVIDER"),
+		OfflineDimension:   getEnvInt("OFFLINE_EMBEDDING_DIMENSION", 512),
+		WatchInterval:      getEnvDuration("FOODS_WATCH_INTERVAL", 0),
+		AdminToken:         os.Getenv("ADMIN_TOKEN"),
+		AuditLogPath:       getEnv("AUDIT_LOG_PATH", "data/audit.log"),
+		DuplicateThreshold: getEnvFloat("DUPLICATE_THRESHOLD", 0.95),
+		SemanticWeight:     getEnvFloat("HYBRID_SEMANTIC_WEIGHT", 0.6),
+		LexicalWeight:      getEnvFloat("HYBRID_LEXICAL_WEIGHT", 0.4),
+		Strategy:           os.Getenv("RECOMMENDER_STRATEGY"),
+		Exploration:        os.Getenv("EXPLORATION"),
+		ExplorationLambda:  getEnvFloat("EXPLORATION_LAMBDA", 0.7),
+		ExplorationWindow:  getEnvInt("EXPLORATION_WINDOW", 5),
+		ExplorationEpsilon: getEnvFloat("EXPLORATION_EPSILON", 0.1),
+		ExplorationTemp:    getEnvFloat("EXPLORATION_TEMPERATURE", 0.05),
+		ColdStartClusters:  getEnvInt("COLD_START_CLUSTERS", 8),
+		IntentAlpha:        getEnvFloat("INTENT_ALPHA", 1),
+		IntentBeta:         getEnvFloat("INTENT_BETA", 0.75),
+		IntentGamma:        getEnvFloat("INTENT_GAMMA", 0.15),
+		LeftSwipeWeight:    getEnvFloat("LEFT_SWIPE_WEIGHT", -0.5),
+		RightSwipeWeight:   getEnvFloat("RIGHT_SWIPE_WEIGHT", 0.2),
+		SuperSwipeWeight:   getEnvFloat("SUPER_SWIPE_WEIGHT", 1.0),
+		RecencyDecay:       getEnvFloat("RECENCY_DECAY", 1),
+		RecencyWindow:      getEnvInt("RECENCY_WINDOW", 0),
+
+		StrategyRecencyDecay:  make(map[string]float64),
+		StrategyRecencyWindow: make(map[string]int),
+	}
+	for _, name := range envSuffixes("RECENCY_DECAY_") {
+		cfg.StrategyRecencyDecay[strings.ToLower(name)] = getEnvFloat("RECENCY_DECAY_"+name, cfg.RecencyDecay)
+	}
+	for _, name := range envSuffixes("RECENCY_WINDOW_") {
+		cfg.StrategyRecencyWindow[strings.ToLower(name)] = getEnvInt("RECENCY_WINDOW_"+name, cfg.RecencyWindow)
+	}
+
+	// EXPLORATION picked the strategy before RECOMMENDER_STRATEGY existed
+	if cfg.Strategy == "" {
+		cfg.Strategy = "cosine"
+		if cfg.Exploration != "" {
+			cfg.Strategy = legacyStrategy(cfg.Exploration)
+		}
+	}
+
+	// no provider picked: use openai when a key is around, else stay offline
+	if cfg.EmbeddingProvider == "" {
+		if os.Getenv("OPENAI_API_KEY") != "" {
+			cfg.EmbeddingProvider = ProviderOpenAI
+		} else {
+			cfg.EmbeddingProvider = ProviderOffline
+		}
+	}
+
+	return cfg
+}
+
+// rejects settings that contradict each other
+func (c *Config) Validate() error {
+	if c.Exploration != "" && legacyStrategy(c.Exploration) != c.Strategy {
+		return fmt.Errorf("EXPLORATION=%s conflicts with RECOMMENDER_STRATEGY=%s, remove EXPLORATION", c.Exploration, c.Strategy)
+	}
+	return nil
+}
+
+// strategy name for an EXPLORATION value, greedy and epsilon were renamed
+func legacyStrategy(exploration string) string {
+	switch exploration {
+	case "greedy":
+		return "cosine"
+	case "epsilon":
+		return "bandit"
+	}
+	return exploration
+}
+
+// what follows prefix in every set env var that starts with it
+func envSuffixes(prefix string) []string {
+	var suffixes []string
+	for _, kv := range os.Environ() {
+		key, _, _ := strings.Cut(kv, "=")
+		if strings.HasPrefix(key, prefix) && len(key) > len(prefix) {
+			suffixes = append(suffixes, strings.TrimPrefix(key, prefix))
+		}
+	}
+	return suffixes
+}
+
+func getEnv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func getEnvInt(key string, fallback int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return fallback
+	}
+	return n
+}
+
+func getEnvFloat(key string, fallback float64) float64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return fallback
+	}
+	return f
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return fallback
+	}
+	return d
+}
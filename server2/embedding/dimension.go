@@ -0,0 +1,47 @@
+package embedding
+
+import (
+	"fmt"
+	"sync"
+)
+
+// vector size learned from a provider's responses, shared by the HTTP clients
+type LearnedDimension struct {
+	mu sync.Mutex
+	n  int
+}
+
+// remembers the size of the first response and rejects later mismatches
+func (d *LearnedDimension) Learn(n int) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.n == 0 {
+		d.n = n
+		return nil
+	}
+	if n != d.n {
+		return fmt.Errorf("embedding dimension changed from %d to %d", d.n, n)
+	}
+	return nil
+}
+
+// the learned size, 0 before the first response
+func (d *LearnedDimension) Get() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.n
+}
+
+// the learned size, or the size of a probe text embedded with e.
+// e is expected to Learn from its responses, so the probe runs once.
+func (d *LearnedDimension) Probe(e Embedder) (int, error) {
+	if n := d.Get(); n > 0 {
+		return n, nil
+	}
+	vec, err := e.GetEmbedding("dimension probe")
+	if err != nil {
+		return 0, fmt.Errorf("could not discover embedding dimension: %w", err)
+	}
+	return len(vec), nil
+}
@@ -0,0 +1,63 @@
+package embedding
+
+import (
+	"errors"
+	"testing"
+)
+
+// embedder that counts calls and learns like the HTTP clients
+type probedEmbedder struct {
+	dimension LearnedDimension
+	calls     int
+	err       error
+}
+
+func (p *probedEmbedder) GetEmbedding(text string) ([]float64, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	vec := []float64{1, 2, 3}
+	return vec, p.dimension.Learn(len(vec))
+}
+
+func (p *probedEmbedder) Dimension() int { return p.dimension.Get() }
+func (p *probedEmbedder) Model() string  { return "probed" }
+
+func TestLearnedDimension(t *testing.T) {
+	var d LearnedDimension
+	if err := d.Learn(3); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Learn(3); err != nil {
+		t.Errorf("Learn() same size error = %v", err)
+	}
+	if err := d.Learn(4); err == nil {
+		t.Error("Learn() should reject a changed size")
+	}
+	if d.Get() != 3 {
+		t.Errorf("Get() = %d, want 3", d.Get())
+	}
+}
+
+func TestProbeOnce(t *testing.T) {
+	p := &probedEmbedder{}
+	for i := 0; i < 2; i++ {
+		if n, err := p.dimension.Probe(p); n != 3 || err != nil {
+			t.Fatalf("Probe() = %d, %v, want 3", n, err)
+		}
+	}
+	if p.calls != 1 {
+		t.Errorf("Probe() made %d calls, want 1", p.calls)
+	}
+}
+
+func TestProbeFailure(t *testing.T) {
+	p := &probedEmbedder{err: errors.New("down")}
+	if n, err := p.dimension.Probe(p); err == nil {
+		t.Errorf("Probe() = %d, want an error", n)
+	}
+	if p.Dimension() != 0 {
+		t.Errorf("Dimension() = %d after a failed probe, want 0", p.Dimension())
+	}
+}
@@ -0,0 +1,50 @@
+package embedding
+
+import "fmt"
+
+// turns text into vectors, implemented by the openai client and the offline embedder
+type Embedder interface {
+	GetEmbedding(text string) ([]float64, error)
+	Dimension() int
+	Model() string // identifies the vector space, used for caching
+}
+
+// embedder whose vector size may only be known after asking the provider
+type DimensionProber interface {
+	Embedder
+	ProbeDimension() (int, error)
+}
+
+// returns the embedder's vector size, asking the provider if it has to
+func DimensionOf(e Embedder) (int, error) {
+	if p, ok := e.(DimensionProber); ok {
+		return p.ProbeDimension()
+	}
+	if n := e.Dimension(); n > 0 {
+		return n, nil
+	}
+	return 0, fmt.Errorf("embedding dimension of %s is unknown", e.Model())
+}
+
+// embedder that can embed many texts in one call
+type BatchEmbedder interface {
+	Embedder
+	GetEmbeddings(texts []string) ([][]float64, error)
+}
+
+// embeds all texts, batched when the embedder supports it
+func EmbedAll(e Embedder, texts []string) ([][]float64, error) {
+	if b, ok := e.(BatchEmbedder); ok {
+		return b.GetEmbeddings(texts)
+	}
+
+	result := make([][]float64, len(texts))
+	for i, text := range texts {
+		vec, err := e.GetEmbedding(text)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = vec
+	}
+	return result, nil
+}
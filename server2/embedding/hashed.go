@@ -0,0 +1,115 @@
+package embedding
+
+import (
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+const DefaultHashDimension = 512
+
+// model name of the hashed embedder, bump when the hashing changes
+const HashModel = "hashed-bow-v1"
+
+// offline embedder using hashed bag-of-words (no network needed)
+type HashEmbedder struct {
+	dimension int
+}
+
+// creates a new hashed bag-of-words embedder
+func NewHashEmbedder(dimension int) *HashEmbedder {
+	if dimension <= 0 {
+		dimension = DefaultHashDimension
+	}
+	return &HashEmbedder{dimension: dimension}
+}
+
+// hashes every word and word pair of the text into a fixed size vector
+func (e *HashEmbedder) GetEmbedding(text string) ([]float64, error) {
+	vec := make([]float64, e.dimension)
+	tokens := Tokenize(text)
+
+	counts := make(map[string]int)
+	for i, tok := range tokens {
+		counts[tok]++
+		if i > 0 {
+			counts[tokens[i-1]+" "+tok]++
+		}
+	}
+
+	for term, n := range counts {
+		idx, sign := e.bucket(term)
+		vec[idx] += sign * (1 + math.Log(float64(n)))
+	}
+
+	var norm float64
+	for _, v := range vec {
+		norm += v * v
+	}
+	if norm > 0 {
+		norm = math.Sqrt(norm)
+		for i := range vec {
+			vec[i] /= norm
+		}
+	}
+	return vec, nil
+}
+
+// embeds every text, never fails
+func (e *HashEmbedder) GetEmbeddings(texts []string) ([][]float64, error) {
+	result := make([][]float64, len(texts))
+	for i, text := range texts {
+		result[i], _ = e.GetEmbedding(text)
+	}
+	return result, nil
+}
+
+// returns the dimension of the hashed vectors
+func (e *HashEmbedder) Dimension() int {
+	return e.dimension
+}
+
+// returns the model name
+func (e *HashEmbedder) Model() string {
+	return HashModel
+}
+
+// picks the vector slot and sign for a term
+func (e *HashEmbedder) bucket(term string) (int, float64) {
+	h := fnv.New64a()
+	h.Write([]byte(term))
+	sum := h.Sum64()
+
+	sign := 1.0
+	if sum>>63 == 1 {
+		sign = -1.0
+	}
+	return int(sum % uint64(e.dimension)), sign
+}
+
+// words that carry no meaning about the dish
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "the": true, "of": true, "with": true,
+	"in": true, "on": true, "or": true, "for": true, "it": true, "its": true,
+	"it's": true, "is": true, "to": true, "as": true, "at": true, "all": true,
+	"who": true, "that": true, "those": true, "this": true, "but": true, "by": true,
+	"often": true, "yet": true, "without": true, "when": true, "you": true, "from": true,
+}
+
+// lowercases text and splits it into words, dropping stop words
+func Tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+
+	tokens := make([]string, 0, len(fields))
+	for _, f := range fields {
+		f = strings.Trim(f, "'")
+		if f == "" || stopWords[f] {
+			continue
+		}
+		tokens = append(tokens, f)
+	}
+	return tokens
+}
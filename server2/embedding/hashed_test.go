@@ -0,0 +1,68 @@
+package embedding
+
+import "testing"
+
+func TestHashEmbedderDimension(t *testing.T) {
+	e := NewHashEmbedder(64)
+	vec, err := e.GetEmbedding("Spicy Ramen: a Japanese noodle soup")
+	if err != nil {
+		t.Fatalf("GetEmbedding() error = %v", err)
+	}
+	if len(vec) != 64 || e.Dimension() != 64 {
+		t.Errorf("Expected 64 dimensions, got %d (Dimension() = %d)", len(vec), e.Dimension())
+	}
+
+	if NewHashEmbedder(0).Dimension() != DefaultHashDimension {
+		t.Error("Zero dimension should fall back to the default")
+	}
+}
+
+func TestHashEmbedderDeterministic(t *testing.T) {
+	e := NewHashEmbedder(128)
+	a, _ := e.GetEmbedding("Butter Chicken: creamy curry")
+	b, _ := e.GetEmbedding("Butter Chicken: creamy curry")
+
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("Embedding should be deterministic, differs at %d", i)
+		}
+	}
+}
+
+func TestHashEmbedderSimilarity(t *testing.T) {
+	e := NewHashEmbedder(256)
+	curry, _ := e.GetEmbedding("Butter Chicken: rich creamy Indian curry with spices")
+	paneer, _ := e.GetEmbedding("Paneer Butter Masala: creamy Indian curry with paneer")
+	salad, _ := e.GetEmbedding("Caesar Salad: romaine lettuce, croutons and parmesan")
+
+	if CosineSimilarity(curry, paneer) <= CosineSimilarity(curry, salad) {
+		t.Errorf("Curries should be closer to each other than to a salad: %v vs %v",
+			CosineSimilarity(curry, paneer), CosineSimilarity(curry, salad))
+	}
+}
+
+func TestHashEmbedderEmptyText(t *testing.T) {
+	vec, err := NewHashEmbedder(16).GetEmbedding("")
+	if err != nil {
+		t.Fatalf("GetEmbedding() error = %v", err)
+	}
+	for i, v := range vec {
+		if v != 0 {
+			t.Errorf("Empty text should give zero vector, got %v at %d", v, i)
+		}
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	got := Tokenize("It's a Spicy, spicy Ramen!")
+	want := []string{"spicy", "spicy", "ramen"}
+
+	if len(got) != len(want) {
+		t.Fatalf("Tokenize() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Tokenize()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package engine
+
+import (
+	"math/rand"
+	"server2/models"
+)
+
+// picks a cold-start card: the catalog is split into clusters at load, and a
+// session sees one food from every cluster before any cluster repeats. the
+// clusters come in an order shuffled per session, so sessions start in
+// different corners of the menu. returns nil if no cluster has a candidate left.
+func coldStartPick(session *models.Session, clusters [][]string, candidates []*models.FoodWithEmbedding) *models.FoodWithEmbedding {
+	allowed := make(map[string]*models.FoodWithEmbedding, len(candidates))
+	for _, food := range candidates {
+		allowed[food.ID] = food
+	}
+
+	var pick *models.FoodWithEmbedding
+	fewest := -1
+	for _, c := range rand.New(rand.NewSource(session.Seed)).Perm(len(clusters)) {
+		shown := 0
+		var first *models.FoodWithEmbedding
+		for _, id := range clusters[c] {
+			if session.HasSeen(id) {
+				shown++
+			} else if first == nil && allowed[id] != nil {
+				first = allowed[id]
+			}
+		}
+		if first != nil && (fewest < 0 || shown < fewest) {
+			pick, fewest = first, shown
+		}
+	}
+	return pick
+}
@@ -0,0 +1,50 @@
+package engine
+
+import (
+	"server2/models"
+	"testing"
+)
+
+func TestColdStartCoversEveryCluster(t *testing.T) {
+	clusters := [][]string{{"a1", "a2"}, {"b1", "b2"}, {"c1"}, {"d1", "d2"}}
+	clusterOf := map[string]int{}
+	var foods []models.FoodWithEmbedding
+	for c, ids := range clusters {
+		for _, id := range ids {
+			clusterOf[id] = c
+			foods = append(foods, models.FoodWithEmbedding{Food: models.Food{ID: id}})
+		}
+	}
+
+	firsts := map[string]bool{}
+	for seed := int64(0); seed < 10; seed++ {
+		session := models.NewSession("test", 2)
+		session.Seed = seed
+
+		covered := map[int]bool{}
+		for i := 0; i < len(clusters); i++ {
+			var candidates []*models.FoodWithEmbedding
+			for j := range foods {
+				// b1 is off limits, the session should get b2 instead
+				if !session.HasSeen(foods[j].ID) && foods[j].ID != "b1" {
+					candidates = append(candidates, &foods[j])
+				}
+			}
+			food := coldStartPick(session, clusters, candidates)
+			if food == nil {
+				t.Fatalf("Seed %d: no cold-start card for card %d", seed, i)
+			}
+			if covered[clusterOf[food.ID]] {
+				t.Errorf("Seed %d: card %d (%s) repeats a cluster", seed, i, food.ID)
+			}
+			if i == 0 {
+				firsts[food.ID] = true
+			}
+			covered[clusterOf[food.ID]] = true
+			session.MarkSeen(food.ID)
+		}
+	}
+	if len(firsts) < 2 {
+		t.Errorf("Sessions should start in different clusters, all started with %v", firsts)
+	}
+}
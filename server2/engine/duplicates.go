@@ -0,0 +1,30 @@
+package engine
+
+import (
+	"server2/models"
+	"sort"
+)
+
+// two foods whose vectors are almost the same, usually the same dish listed twice
+type DuplicatePair struct {
+	A, B  *models.FoodWithEmbedding
+	Score float64
+}
+
+// returns every pair of foods with cosine similarity at or above threshold, most similar first
+func NearDuplicates(foods []models.FoodWithEmbedding, threshold float64) []DuplicatePair {
+	var pairs []DuplicatePair
+	for i := range foods {
+		for j := i + 1; j < len(foods); j++ {
+			score := CosineSimilarity(foods[i].Embedding, foods[j].Embedding)
+			if score >= threshold {
+				pairs = append(pairs, DuplicatePair{A: &foods[i], B: &foods[j], Score: score})
+			}
+		}
+	}
+
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return pairs[i].Score > pairs[j].Score
+	})
+	return pairs
+}
@@ -0,0 +1,31 @@
+package engine
+
+import (
+	"server2/models"
+	"testing"
+)
+
+func TestNearDuplicates(t *testing.T) {
+	foods := []models.FoodWithEmbedding{
+		{Food: models.Food{ID: "1", Name: "Butter Chicken"}, Embedding: []float64{1, 0, 0}},
+		{Food: models.Food{ID: "2", Name: "Murgh Makhani"}, Embedding: []float64{0.99, 0.1, 0}},
+		{Food: models.Food{ID: "3", Name: "Sushi"}, Embedding: []float64{0, 0, 1}},
+		{Food: models.Food{ID: "4", Name: "Chicken Makhani"}, Embedding: []float64{0.95, 0.3, 0}},
+	}
+
+	pairs := NearDuplicates(foods, 0.95)
+	if len(pairs) != 3 {
+		t.Fatalf("Expected 3 near-duplicate pairs, got %d", len(pairs))
+	}
+	if pairs[0].A.ID != "1" || pairs[0].B.ID != "2" {
+		t.Errorf("Most similar pair should be 1/2, got %s/%s", pairs[0].A.ID, pairs[0].B.ID)
+	}
+	for i := 1; i < len(pairs); i++ {
+		if pairs[i].Score > pairs[i-1].Score {
+			t.Error("Pairs should be sorted by score")
+		}
+	}
+	if len(NearDuplicates(foods, 0.9999)) != 0 {
+		t.Error("No pair should pass a near-exact threshold")
+	}
+}
@@ -0,0 +1,36 @@
+package engine
+
+// how cosine similarity and BM25 keyword scores are mixed
+type HybridWeights struct {
+	Semantic float64 `json:"semantic"`
+	Lexical  float64 `json:"lexical"`
+}
+
+// used when no weights are configured
+var DefaultHybridWeights = HybridWeights{Semantic: 0.6, Lexical: 0.4}
+
+// blends a cosine score with a BM25 score already scaled to [0, 1]
+func (w HybridWeights) Blend(cosine, lexical float64) float64 {
+	if w.Semantic == 0 && w.Lexical == 0 {
+		w = DefaultHybridWeights
+	}
+	return w.Semantic*cosine + w.Lexical*lexical
+}
+
+// scales BM25 scores by the best one so they are comparable with cosine similarity
+func NormalizeScores(scores map[string]float64) map[string]float64 {
+	var best float64
+	for _, s := range scores {
+		if s > best {
+			best = s
+		}
+	}
+	normalized := make(map[string]float64, len(scores))
+	if best == 0 {
+		return normalized
+	}
+	for id, s := range scores {
+		normalized[id] = s / best
+	}
+	return normalized
+}
@@ -0,0 +1,26 @@
+package engine
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHybridBlend(t *testing.T) {
+	w := HybridWeights{Semantic: 0.7, Lexical: 0.3}
+	if got := w.Blend(0.5, 1); math.Abs(got-0.65) > 1e-9 {
+		t.Errorf("Blend() = %v, want 0.65", got)
+	}
+	if got := (HybridWeights{}).Blend(1, 0); got != DefaultHybridWeights.Semantic {
+		t.Errorf("Zero weights should fall back to the defaults, got %v", got)
+	}
+}
+
+func TestNormalizeScores(t *testing.T) {
+	got := NormalizeScores(map[string]float64{"a": 4, "b": 1})
+	if got["a"] != 1 || got["b"] != 0.25 {
+		t.Errorf("NormalizeScores() = %v", got)
+	}
+	if len(NormalizeScores(nil)) != 0 {
+		t.Error("Empty scores should stay empty")
+	}
+}
@@ -0,0 +1,58 @@
+package engine
+
+import "sync"
+
+// swipe counts per food, kept in memory like the sessions
+type Popularity struct {
+	mu     sync.RWMutex
+	counts map[string]map[string]*swipeCounts // catalog -> food ID -> counts
+}
+
+type swipeCounts struct {
+	likes  int // right and super swipes
+	swipes int
+}
+
+// creates an empty tracker
+func NewPopularity() *Popularity {
+	return &Popularity{counts: make(map[string]map[string]*swipeCounts)}
+}
+
+// counts a swipe on a food
+func (p *Popularity) Record(catalog, foodID, action string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	foods := p.counts[catalog]
+	if foods == nil {
+		foods = make(map[string]*swipeCounts)
+		p.counts[catalog] = foods
+	}
+	c := foods[foodID]
+	if c == nil {
+		c = &swipeCounts{}
+		foods[foodID] = c
+	}
+	c.swipes++
+	if action == "right" || action == "super" {
+		c.likes++
+	}
+}
+
+// share of swipes on a food that were likes, smoothed so unswiped foods score 0.5
+func (p *Popularity) Score(catalog, foodID string) float64 {
+	if p == nil {
+		return 0.5
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	c := p.counts[catalog][foodID]
+	if c == nil {
+		return 0.5
+	}
+	return float64(c.likes+1) / float64(c.swipes+2)
+}
@@ -0,0 +1,80 @@
+package engine
+
+import (
+	"fmt"
+	"server2/models"
+)
+
+// how fast older swipes lose their pull on the intent
+type RecencyConfig struct {
+	Decay  float64 // every swipe scales the earlier ones by this, 1 keeps them at full weight
+	Window int     // only the last Window swipes count, 0 counts all of them
+}
+
+// used by DefaultConfig: every swipe counts the same
+var DefaultRecency = RecencyConfig{Decay: 1}
+
+// checks the decay and window are in range
+func (c RecencyConfig) Validate() error {
+	if c.Decay <= 0 || c.Decay > 1 {
+		return fmt.Errorf("recency decay must be above 0 and at most 1, got %v", c.Decay)
+	}
+	if c.Window < 0 {
+		return fmt.Errorf("recency window must not be negative, got %d", c.Window)
+	}
+	return nil
+}
+
+// the recency settings of the session's strategy, the deployment ones
+// if the strategy has none of its own, the defaults when none are set
+func (r *Recommender) recencyFor(session *models.Session) RecencyConfig {
+	recency, ok := r.config.StrategyRecency[r.StrategyFor(session)]
+	if !ok {
+		recency = r.config.Recency
+	}
+	if recency == (RecencyConfig{}) {
+		return DefaultRecency
+	}
+	return recency
+}
+
+// rebuilds the session's centroids from its swipe history, oldest first,
+// keeping the last recency.Window swipes and fading each by recency.Decay.
+// swipes on foods without an embedding are skipped.
+func (r *Recommender) replayFeedback(session *models.Session, recency RecencyConfig, embeddingOf func(foodID string) []float64) {
+	config := r.intentConfig()
+	swipes := session.GetSwipes()
+	if recency.Window > 0 && len(swipes) > recency.Window {
+		swipes = swipes[len(swipes)-recency.Window:]
+	}
+
+	session.ResetFeedback()
+	for _, swipe := range swipes {
+		embedding := embeddingOf(swipe.FoodID)
+		weight, valid := config.swipeWeight(swipe.Action)
+		if embedding == nil || !valid {
+			continue
+		}
+		session.DecayFeedback(recency.Decay)
+		session.AddFeedback(embedding, weight)
+	}
+}
+
+// looks up food embeddings in the session's catalog, falling back to the
+// food just swiped when the catalog is unavailable
+func (r *Recommender) catalogEmbeddings(session *models.Session, swiped *models.FoodWithEmbedding) func(string) []float64 {
+	return func(id string) []float64 {
+		if id == swiped.ID {
+			return swiped.Embedding
+		}
+		if r.catalogs == nil {
+			return nil
+		}
+		if foodStore := r.catalogs.Get(session.Catalog); foodStore != nil {
+			if food := foodStore.GetByID(id); food != nil && len(food.Embedding) == len(swiped.Embedding) {
+				return food.Embedding
+			}
+		}
+		return nil
+	}
+}
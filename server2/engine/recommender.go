@@ -1,103 +1,269 @@
 package engine
 
 import (
+	"fmt"
 	"math"
+	"server2/embedding"
 	"server2/models"
 	"server2/store"
-)
-
-// swipe action weights
-const (
-	LeftSwipeWeight  = -0.5 // strong negative
-	RightSwipeWeight = 0.2  // weak positive
-	SuperSwipeWeight = 1.0  // strong positive
+	"sort"
 )
 
 // handles food recommendation logic
 type Recommender struct {
-	foodStore *store.FoodStore
+	catalogs   *store.Catalogs
+	config     Config
+	popularity *Popularity
 }
 
-// creates a new recommender
-func NewRecommender(foodStore *store.FoodStore) *Recommender {
-	return &Recommender{foodStore: foodStore}
+// recommender settings
+type Config struct {
+	Strategy    string        // strategy for sessions that pick none, cosine when empty
+	Hybrid      HybridWeights // mix of cosine and keyword scores for sessions seeded with text
+	Exploration ExplorationConfig
+	Intent      IntentConfig // how swipes and the query combine into the intent
+
+	Recency         RecencyConfig            // how fast older swipes fade
+	StrategyRecency map[string]RecencyConfig // overrides Recency for sessions on these strategies
 }
 
-// returns the best unseen food for a session
-func (r *Recommender) GetNextRecommendation(session *models.Session) *models.FoodWithEmbedding {
-	intent := session.GetIntent()
-	foods := r.foodStore.GetAll()
+// settings used by NewRecommender
+func DefaultConfig() Config {
+	return Config{
+		Strategy:    StrategyCosine,
+		Hybrid:      DefaultHybridWeights,
+		Exploration: DefaultExploration,
+		Intent:      DefaultIntent,
+		Recency:     DefaultRecency,
+	}
+}
 
-	var bestFood *models.FoodWithEmbedding
-	bestScore := math.Inf(-1)  // start at lowest possible score
+// checks the default strategy is registered and the tuning is in range
+func (c Config) Validate() error {
+	if c.Strategy != "" && !HasStrategy(c.Strategy) {
+		return fmt.Errorf("unknown strategy %q", c.Strategy)
+	}
+	if err := c.Intent.Validate(); err != nil {
+		return err
+	}
+	if err := c.Recency.Validate(); err != nil {
+		return err
+	}
+	for name, recency := range c.StrategyRecency {
+		if !HasStrategy(name) {
+			return fmt.Errorf("recency set for unknown strategy %q", name)
+		}
+		if err := recency.Validate(); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return c.Exploration.Validate()
+}
 
-	isNeutral := IsZeroVector(intent)  // check if user has no preferences yet
+// creates a new recommender with the default settings
+func NewRecommender(catalogs *store.Catalogs) *Recommender {
+	return NewRecommenderWithConfig(catalogs, DefaultConfig())
+}
 
+// creates a new recommender with custom settings
+func NewRecommenderWithConfig(catalogs *store.Catalogs, config Config) *Recommender {
+	if config.Strategy == "" {
+		config.Strategy = StrategyCosine
+	}
+	return &Recommender{catalogs: catalogs, config: config, popularity: NewPopularity()}
+}
+
+// returns the recommender's settings
+func (r *Recommender) Config() Config {
+	return r.config
+}
+
+// name of the strategy a session is scored with
+func (r *Recommender) StrategyFor(session *models.Session) string {
+	if session.Strategy != "" {
+		return session.Strategy
+	}
+	return r.config.Strategy
+}
+
+// returns the best unseen food for a session, from the session's catalog,
+// as scored by the session's strategy
+func (r *Recommender) GetNextRecommendation(session *models.Session) *models.FoodWithEmbedding {
+	foodStore := r.catalogs.Get(session.Catalog)
+	if foodStore == nil {
+		return nil
+	}
+	strategy, err := NewStrategy(r.StrategyFor(session), r.config)
+	if err != nil {
+		return nil
+	}
+	foods, mode := foodStore.Snapshot()
+
+	state := &State{
+		Session:    session,
+		Intent:     r.syncIntent(session, foods, mode),
+		Foods:      make(map[string]*models.FoodWithEmbedding, len(foods)),
+		Popularity: r.popularity,
+		Rand:       sessionRand(session),
+		hybrid:     r.config.Hybrid,
+		position:   make(map[string]int, len(foods)),
+	}
+	// sessions seeded with text also reward foods naming its keywords
+	if session.Query != "" {
+		state.Lexical = NormalizeScores(foodStore.BM25().Scores(session.Query))
+	}
+
+	candidates := make([]*models.FoodWithEmbedding, 0, len(foods))
 	for i := range foods {
 		food := &foods[i]
+		state.Foods[food.ID] = food
+		state.position[food.ID] = i
 
-		if session.HasSeen(food.ID) {
+		if session.HasSeen(food.ID) || !session.Constraints.Allows(&food.Food) {
 			continue
 		}
+		candidates = append(candidates, food)
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	// until the session shows a preference its cards cover the whole menu,
+	// one per cluster. after that the strategy and the swipes decide.
+	if session.Query == "" && state.Neutral() {
+		if food := coldStartPick(session, foodStore.Clusters(), candidates); food != nil {
+			return food
+		}
+	}
 
-		var score float64
-		if isNeutral {
-			score = float64(len(foods) - i)
-		} else {
-			score = CosineSimilarity(intent, food.Embedding)
+	// highest score wins, the earlier food on ties
+	scores := strategy.Score(state, candidates)
+	best := 0
+	for i := range candidates {
+		if scores[i] > scores[best] {
+			best = i
 		}
+	}
+	return candidates[best]
+}
 
-		if score > bestScore {
-			bestScore = score
-			bestFood = food
+// a food and how close it is to another food
+type ScoredFood struct {
+	Food  *models.FoodWithEmbedding
+	Score float64
+}
+
+// returns the k foods of a catalog closest to the given food by cosine similarity
+func (r *Recommender) SimilarFoods(foodStore *store.FoodStore, food *models.FoodWithEmbedding, k int) []ScoredFood {
+	foods := foodStore.GetAll()
+
+	scored := make([]ScoredFood, 0, len(foods))
+	for i := range foods {
+		if foods[i].ID == food.ID {
+			continue
 		}
+		scored = append(scored, ScoredFood{
+			Food:  &foods[i],
+			Score: CosineSimilarity(food.Embedding, foods[i].Embedding),
+		})
 	}
 
-	return bestFood
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].Score > scored[j].Score
+	})
+	if k >= 0 && len(scored) > k {
+		scored = scored[:k]
+	}
+	return scored
 }
 
 // computes cosine similarity between two vectors
 func CosineSimilarity(a, b []float64) float64 {
-	if len(a) != len(b) || len(a) == 0 {
-		return 0
-	}
+	return embedding.CosineSimilarity(a, b)
+}
 
-	var dot, normA, normB float64
-	for i := range a {
-		dot += a[i] * b[i]
-		normA += a[i] * a[i]
-		normB += b[i] * b[i]
+
+//  updates the session intent based on swipe action
+func (r *Recommender) UpdateIntent(session *models.Session, food *models.FoodWithEmbedding, action string) {
+	weight, ok := r.intentConfig().swipeWeight(action)
+	if !ok {
+		return
 	}
+	session.RecordSwipe(food.ID, action)
+	r.popularity.Record(session.Catalog, food.ID, action)
 
-	if normA == 0 || normB == 0 {
-		return 0
+	// a window needs the older swipes back, decay alone folds in as it goes
+	if recency := r.recencyFor(session); recency.Window > 0 {
+		r.replayFeedback(session, recency, r.catalogEmbeddings(session, food))
+	} else {
+		session.DecayFeedback(recency.Decay)
+		session.AddFeedback(food.Embedding, weight)
 	}
+	session.UpdateIntent(r.composeIntent(session, len(food.Embedding)))
+}
 
-	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+// points a new session's intent at its query text, embedded in the
+// session's catalog
+func (r *Recommender) SeedIntent(session *models.Session) error {
+	foodStore := r.catalogs.Get(session.Catalog)
+	if foodStore == nil {
+		return store.ErrCatalogNotFound
+	}
+	_, mode := foodStore.Snapshot()
+	vector, err := foodStore.EmbedQueryIn(mode, session.Query)
+	if err != nil {
+		return fmt.Errorf("failed to embed session query: %w", err)
+	}
+	session.SetQueryVector(NormalizeVector(vector))
+	session.SetIntent(mode, r.composeIntent(session, len(vector)))
+	return nil
 }
 
+// the session query embedded in the space of mode, nil if it has none or
+// it cannot be embedded right now
+func (r *Recommender) embedQuery(session *models.Session, mode string, dimension int) []float64 {
+	if session.Query != "" && r.catalogs != nil {
+		if foodStore := r.catalogs.Get(session.Catalog); foodStore != nil {
+			vector, err := foodStore.EmbedQueryIn(mode, session.Query)
+			if err == nil && len(vector) == dimension {
+				return NormalizeVector(vector)
+			}
+		}
+	}
+	return nil
+}
 
-//  updates the session intent based on swipe action
-func (r *Recommender) UpdateIntent(session *models.Session, food *models.FoodWithEmbedding, action string) {
+// returns the session intent in the catalog's current vector space.
+// when the catalog switched modes (e.g. lexical fallback -> embeddings)
+// the intent is rebuilt from the session's query and swipes.
+func (r *Recommender) syncIntent(session *models.Session, foods []models.FoodWithEmbedding, mode string) []float64 {
 	intent := session.GetIntent()
+	if len(foods) == 0 {
+		return intent
+	}
 
-	var weight float64
-	switch action {
-	case "left":
-		weight = LeftSwipeWeight
-	case "right":
-		weight = RightSwipeWeight
-	case "super":
-		weight = SuperSwipeWeight
-	default:
-		return
+	dimension := len(foods[0].Embedding)
+	if session.GetIntentSpace() == mode && len(intent) == dimension {
+		return intent
 	}
 
-	newIntent := AddVectors(intent, ScaleVector(food.Embedding, weight))
-	newIntent = NormalizeVector(newIntent)
-	session.UpdateIntent(newIntent)
-}
+	byID := make(map[string]*models.FoodWithEmbedding, len(foods))
+	for i := range foods {
+		byID[foods[i].ID] = &foods[i]
+	}
+
+	session.SetQueryVector(r.embedQuery(session, mode, dimension))
+	r.replayFeedback(session, r.recencyFor(session), func(id string) []float64 {
+		if food, ok := byID[id]; ok {
+			return food.Embedding
+		}
+		return nil
+	})
 
+	intent = r.composeIntent(session, dimension)
+	session.SetIntent(mode, intent)
+	return intent
+}
 
 // adds two vectors element-wise
 func AddVectors(a, b []float64) []float64 {
@@ -214,3 +214,111 @@ func TestZeroVectorDetection(t *testing.T) {
 		t.Error("Should not detect non-zero vector as zero")
 	}
 }
+
+func TestSyncIntentReplaysSwipesOnModeChange(t *testing.T) {
+	session := models.NewSession("test", 2)
+	session.SetIntent("lexical", []float64{0, 1})
+	session.RecordSwipe("1", "right")
+	session.RecordSwipe("2", "left")
+
+	foods := []models.FoodWithEmbedding{
+		{Food: models.Food{ID: "1"}, Embedding: []float64{1, 0, 0}},
+		{Food: models.Food{ID: "2"}, Embedding: []float64{0, 0, 1}},
+		{Food: models.Food{ID: "3"}, Embedding: []float64{0.9, 0, -0.1}},
+	}
+
+	r := &Recommender{}
+	intent := r.syncIntent(session, foods, "embedding")
+
+	if len(intent) != 3 {
+		t.Fatalf("Intent should be rebuilt in the new dimension, got %d", len(intent))
+	}
+	if session.GetIntentSpace() != "embedding" {
+		t.Errorf("IntentSpace = %q, want embedding", session.GetIntentSpace())
+	}
+	if CosineSimilarity(intent, foods[2].Embedding) <= CosineSimilarity(intent, foods[1].Embedding) {
+		t.Error("Replayed intent should prefer the liked direction over the disliked one")
+	}
+}
+
+func TestRecentLeftSwipeOutweighsOldRightSwipe(t *testing.T) {
+	curry := &models.FoodWithEmbedding{Food: models.Food{ID: "curry"}, Embedding: []float64{1, 0, 0}}
+	korma := &models.FoodWithEmbedding{Food: models.Food{ID: "korma"}, Embedding: []float64{0.9, 0, 0.1}}
+
+	swipe := func(r *Recommender) float64 {
+		session := models.NewSession("test", 3)
+		r.UpdateIntent(session, curry, "right")
+		r.UpdateIntent(session, korma, "left")
+		return CosineSimilarity(session.GetIntent(), curry.Embedding)
+	}
+
+	if sim := swipe(NewRecommender(nil)); sim <= 0 {
+		t.Fatalf("Without decay the old like should still win, got %v", sim)
+	}
+
+	config := DefaultConfig()
+	config.Recency = RecencyConfig{Decay: 0.5}
+	if sim := swipe(NewRecommenderWithConfig(nil, config)); sim <= 0 || sim >= swipe(NewRecommender(nil)) {
+		t.Errorf("Decay 0.5 should weaken the old like without flipping it, got %v", sim)
+	}
+	config.Recency = RecencyConfig{Decay: 0.25}
+	if sim := swipe(NewRecommenderWithConfig(nil, config)); sim >= 0 {
+		t.Errorf("With decay the recent left swipe should outweigh the old right swipe, got %v", sim)
+	}
+
+	// only sessions on mmr decay
+	config = DefaultConfig()
+	config.StrategyRecency = map[string]RecencyConfig{StrategyMMR: {Decay: 0.25}}
+	r := NewRecommenderWithConfig(nil, config)
+	if sim := swipe(r); sim <= 0 {
+		t.Errorf("Cosine sessions should not decay, got %v", sim)
+	}
+	session := models.NewSession("test", 3)
+	session.Strategy = StrategyMMR
+	r.UpdateIntent(session, curry, "right")
+	r.UpdateIntent(session, korma, "left")
+	if CosineSimilarity(session.GetIntent(), curry.Embedding) >= 0 {
+		t.Error("MMR sessions should use their own decay")
+	}
+}
+
+func TestRecencyWindow(t *testing.T) {
+	embeddings := map[string][]float64{
+		"curry": {1, 0, 0},
+		"sushi": {0, 1, 0},
+		"korma": {0.9, 0, 0.1},
+	}
+	session := models.NewSession("test", 3)
+	session.RecordSwipe("curry", "right")
+	session.RecordSwipe("sushi", "right")
+	session.RecordSwipe("korma", "left")
+
+	r := NewRecommender(nil)
+	r.replayFeedback(session, RecencyConfig{Decay: 1, Window: 2}, func(id string) []float64 { return embeddings[id] })
+
+	positive, negative := session.Feedback()
+	if positive[0] != 0 || positive[1] == 0 {
+		t.Errorf("Only sushi should be left in the liked centroid, got %v", positive)
+	}
+	if negative == nil {
+		t.Error("The last swipe should be inside the window")
+	}
+}
+
+func TestRecencyValidate(t *testing.T) {
+	config := DefaultConfig()
+	config.Recency = RecencyConfig{Decay: 1.5}
+	if config.Validate() == nil {
+		t.Error("Decay above 1 should be rejected")
+	}
+	config.Recency = RecencyConfig{Decay: 0, Window: 3}
+	if config.Validate() == nil {
+		t.Error("Decay 0 should be rejected, it would drop every earlier swipe")
+	}
+
+	config = DefaultConfig()
+	config.StrategyRecency = map[string]RecencyConfig{"oracle": {Decay: 0.9}}
+	if config.Validate() == nil {
+		t.Error("Recency for an unknown strategy should be rejected")
+	}
+}
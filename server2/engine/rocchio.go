@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"fmt"
+	"server2/models"
+)
+
+// how the intent is built from the session query and swipes, Rocchio style:
+// intent = Alpha·query + Beta·positive − Gamma·negative, where positive and
+// negative are the centroids of the foods swiped right (or super) and left,
+// each food scaled by its swipe weight. the intent does not depend on the
+// order of the swipes, and one left swipe cannot cancel out several likes.
+type IntentConfig struct {
+	Alpha float64 // weight of the seeded query
+	Beta  float64 // weight of the positive centroid
+	Gamma float64 // weight of the negative centroid
+
+	Left  float64 // swipe weights, negative values count toward the negative centroid
+	Right float64
+	Super float64
+}
+
+// used by DefaultConfig
+var DefaultIntent = IntentConfig{
+	Alpha: 1,
+	Beta:  0.75,
+	Gamma: 0.15,
+
+	Left:  -0.5, // strong negative
+	Right: 0.2,  // weak positive
+	Super: 1.0,  // strong positive
+}
+
+// checks the coefficients make sense
+func (c IntentConfig) Validate() error {
+	if c.Alpha < 0 || c.Beta < 0 || c.Gamma < 0 {
+		return fmt.Errorf("intent coefficients must not be negative, got alpha %v, beta %v, gamma %v", c.Alpha, c.Beta, c.Gamma)
+	}
+	if c.Left >= 0 {
+		return fmt.Errorf("left swipe weight must be negative, got %v", c.Left)
+	}
+	if c.Right <= 0 || c.Super <= 0 {
+		return fmt.Errorf("right and super swipe weights must be positive, got %v and %v", c.Right, c.Super)
+	}
+	return nil
+}
+
+// the configured intent settings, the defaults when none are set
+func (r *Recommender) intentConfig() IntentConfig {
+	if r.config.Intent == (IntentConfig{}) {
+		return DefaultIntent
+	}
+	return r.config.Intent
+}
+
+// maps a swipe action to its weight
+func (c IntentConfig) swipeWeight(action string) (float64, bool) {
+	switch action {
+	case "left":
+		return c.Left, true
+	case "right":
+		return c.Right, true
+	case "super":
+		return c.Super, true
+	}
+	return 0, false
+}
+
+// combines the session's query and centroids into a unit intent vector
+func (r *Recommender) composeIntent(session *models.Session, dimension int) []float64 {
+	c := r.intentConfig()
+	intent := make([]float64, dimension)
+
+	positive, negative := session.Feedback()
+	for _, term := range []struct {
+		v      []float64
+		weight float64
+	}{
+		{session.GetQueryVector(), c.Alpha},
+		{positive, c.Beta},
+		{negative, -c.Gamma},
+	} {
+		if len(term.v) == dimension {
+			intent = AddVectors(intent, ScaleVector(term.v, term.weight))
+		}
+	}
+	return NormalizeVector(intent)
+}
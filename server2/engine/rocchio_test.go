@@ -0,0 +1,91 @@
+package engine
+
+import (
+	"math"
+	"server2/models"
+	"testing"
+)
+
+func rocchioFoods() []*models.FoodWithEmbedding {
+	return []*models.FoodWithEmbedding{
+		{Food: models.Food{ID: "curry"}, Embedding: []float64{1, 0, 0}},
+		{Food: models.Food{ID: "korma"}, Embedding: []float64{0.9, 0.1, 0}},
+		{Food: models.Food{ID: "sushi"}, Embedding: []float64{0, 1, 0}},
+		{Food: models.Food{ID: "vindaloo"}, Embedding: []float64{0.8, 0, 0.2}},
+	}
+}
+
+func TestRocchioIntentIgnoresSwipeOrder(t *testing.T) {
+	foods := rocchioFoods()
+	swipes := []struct {
+		food   *models.FoodWithEmbedding
+		action string
+	}{
+		{foods[0], "right"}, {foods[2], "left"}, {foods[1], "super"}, {foods[3], "left"},
+	}
+
+	r := NewRecommender(nil)
+	forward := models.NewSession("forward", 3)
+	backward := models.NewSession("backward", 3)
+	for i := range swipes {
+		r.UpdateIntent(forward, swipes[i].food, swipes[i].action)
+		r.UpdateIntent(backward, swipes[len(swipes)-1-i].food, swipes[len(swipes)-1-i].action)
+	}
+
+	a, b := forward.GetIntent(), backward.GetIntent()
+	for i := range a {
+		if math.Abs(a[i]-b[i]) > 1e-9 {
+			t.Fatalf("Intent should not depend on swipe order: %v vs %v", a, b)
+		}
+	}
+}
+
+func TestOneLeftSwipeDoesNotCancelLikes(t *testing.T) {
+	foods := rocchioFoods()
+	r := NewRecommender(nil)
+	session := models.NewSession("test", 3)
+
+	r.UpdateIntent(session, foods[0], "right")
+	r.UpdateIntent(session, foods[1], "right")
+	r.UpdateIntent(session, foods[3], "left")
+
+	if sim := CosineSimilarity(session.GetIntent(), foods[0].Embedding); sim <= 0 {
+		t.Errorf("Two likes should still point toward curries after one left swipe, got %v", sim)
+	}
+}
+
+func TestRocchioCoefficients(t *testing.T) {
+	foods := rocchioFoods()
+	session := models.NewSession("test", 3)
+	session.SetQueryVector([]float64{0, 1, 0})
+
+	config := DefaultConfig()
+	config.Intent.Alpha = 0
+	r := NewRecommenderWithConfig(nil, config)
+	r.UpdateIntent(session, foods[0], "right")
+	if got := session.GetIntent(); math.Abs(got[0]-1) > 1e-9 {
+		t.Errorf("With alpha 0 the query should be ignored, got %v", got)
+	}
+
+	r = NewRecommender(nil)
+	r.UpdateIntent(session, foods[1], "right")
+	if CosineSimilarity(session.GetIntent(), foods[2].Embedding) <= 0 {
+		t.Error("The seeded query should keep pulling toward sushi")
+	}
+}
+
+func TestIntentConfigValidate(t *testing.T) {
+	if err := DefaultIntent.Validate(); err != nil {
+		t.Errorf("Defaults should be valid: %v", err)
+	}
+	bad := []IntentConfig{
+		{Alpha: -1, Left: -1, Right: 1, Super: 1},
+		{Left: 0.5, Right: 1, Super: 1},
+		{Left: -1, Right: 0, Super: 1},
+	}
+	for _, c := range bad {
+		if c.Validate() == nil {
+			t.Errorf("%+v should be rejected", c)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"server2/models"
+)
+
+// tuning for the exploring strategies
+type ExplorationConfig struct {
+	Lambda      float64 // mmr: weight of relevance against diversity, 1 is plain cosine
+	Window      int     // mmr: how many recently shown cards to stay away from
+	Epsilon     float64 // bandit: probability of a random pick
+	Temperature float64 // softmax: lower is greedier
+}
+
+// used by DefaultConfig
+var DefaultExploration = ExplorationConfig{
+	Lambda:      0.7,
+	Window:      5,
+	Epsilon:     0.1,
+	Temperature: 0.05,
+}
+
+// checks the parameters are in range
+func (e ExplorationConfig) Validate() error {
+	switch {
+	case e.Lambda < 0 || e.Lambda > 1:
+		return fmt.Errorf("mmr lambda must be between 0 and 1, got %v", e.Lambda)
+	case e.Window < 0:
+		return fmt.Errorf("mmr window must not be negative, got %d", e.Window)
+	case e.Epsilon < 0 || e.Epsilon > 1:
+		return fmt.Errorf("epsilon must be between 0 and 1, got %v", e.Epsilon)
+	case e.Temperature <= 0:
+		return fmt.Errorf("softmax temperature must be positive, got %v", e.Temperature)
+	}
+	return nil
+}
+
+// greedy: the food closest to the intent
+type cosineStrategy struct{}
+
+func (cosineStrategy) Score(state *State, candidates []*models.FoodWithEmbedding) []float64 {
+	scores := make([]float64, len(candidates))
+	for i, food := range candidates {
+		scores[i] = state.Relevance(food)
+	}
+	return scores
+}
+
+// maximal marginal relevance: λ·relevance − (1−λ)·max similarity to a recent card
+type mmrStrategy struct {
+	lambda float64
+	window int
+}
+
+func (m mmrStrategy) Score(state *State, candidates []*models.FoodWithEmbedding) []float64 {
+	scores := cosineStrategy{}.Score(state, candidates)
+	if state.Neutral() {
+		return scores
+	}
+
+	recent := state.RecentlyShown(m.window)
+	for i, food := range candidates {
+		redundancy := 0.0
+		for j, shown := range recent {
+			if sim := CosineSimilarity(food.Embedding, shown.Embedding); j == 0 || sim > redundancy {
+				redundancy = sim
+			}
+		}
+		scores[i] = m.lambda*scores[i] - (1-m.lambda)*redundancy
+	}
+	return scores
+}
+
+// epsilon-greedy: usually the closest food, now and then a random one
+type banditStrategy struct {
+	epsilon float64
+}
+
+func (b banditStrategy) Score(state *State, candidates []*models.FoodWithEmbedding) []float64 {
+	scores := cosineStrategy{}.Score(state, candidates)
+	if state.Neutral() || state.Rand.Float64() >= b.epsilon {
+		return scores
+	}
+	for i := range scores {
+		scores[i] = state.Rand.Float64()
+	}
+	return scores
+}
+
+// samples a food with probability proportional to exp(relevance / temperature).
+// adds Gumbel noise to the scaled scores, so their argmax is that sample.
+type softmaxStrategy struct {
+	temperature float64
+}
+
+func (s softmaxStrategy) Score(state *State, candidates []*models.FoodWithEmbedding) []float64 {
+	scores := cosineStrategy{}.Score(state, candidates)
+	if state.Neutral() || s.temperature <= 0 {
+		return scores
+	}
+	for i := range scores {
+		u := state.Rand.Float64()
+		for u == 0 {
+			u = state.Rand.Float64()
+		}
+		scores[i] = scores[i]/s.temperature - math.Log(-math.Log(u))
+	}
+	return scores
+}
+
+// the foods liked most across the catalog's sessions, for sessions that
+// should see crowd favourites. relevance only breaks ties.
+type popularityStrategy struct{}
+
+func (popularityStrategy) Score(state *State, candidates []*models.FoodWithEmbedding) []float64 {
+	scores := cosineStrategy{}.Score(state, candidates)
+	for i, food := range candidates {
+		scores[i] = state.Popularity.Score(state.Session.Catalog, food.ID) + scores[i]*1e-3
+	}
+	return scores
+}
@@ -0,0 +1,127 @@
+package engine
+
+import (
+	"fmt"
+	"math/rand"
+	"server2/models"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// built-in strategy names
+const (
+	StrategyCosine     = "cosine"     // closest to the intent
+	StrategyMMR        = "mmr"        // closest, penalized by similarity to recently shown cards
+	StrategyBandit     = "bandit"     // epsilon-greedy: a random card with probability Epsilon
+	StrategySoftmax    = "softmax"    // sampled with probability proportional to exp(relevance / Temperature)
+	StrategyPopularity = "popularity" // most liked across sessions, relevance breaks ties
+)
+
+// scores the foods a session may be shown next, the recommender shows the
+// highest score. scores only need to be comparable within one call.
+type Strategy interface {
+	Score(state *State, candidates []*models.FoodWithEmbedding) []float64
+}
+
+// builds a strategy from the recommender settings
+type StrategyFactory func(config Config) Strategy
+
+var (
+	strategiesMu sync.RWMutex
+	strategies   = map[string]StrategyFactory{
+		StrategyCosine:     func(Config) Strategy { return cosineStrategy{} },
+		StrategyMMR:        func(c Config) Strategy { return mmrStrategy{c.Exploration.Lambda, c.Exploration.Window} },
+		StrategyBandit:     func(c Config) Strategy { return banditStrategy{c.Exploration.Epsilon} },
+		StrategySoftmax:    func(c Config) Strategy { return softmaxStrategy{c.Exploration.Temperature} },
+		StrategyPopularity: func(Config) Strategy { return popularityStrategy{} },
+	}
+)
+
+// adds or replaces a strategy, call before serving requests
+func RegisterStrategy(name string, factory StrategyFactory) {
+	strategiesMu.Lock()
+	defer strategiesMu.Unlock()
+	strategies[name] = factory
+}
+
+// registered strategy names, sorted
+func Strategies() []string {
+	strategiesMu.RLock()
+	defer strategiesMu.RUnlock()
+	names := make([]string, 0, len(strategies))
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// true if a strategy is registered under the name
+func HasStrategy(name string) bool {
+	strategiesMu.RLock()
+	defer strategiesMu.RUnlock()
+	_, ok := strategies[name]
+	return ok
+}
+
+// builds a registered strategy
+func NewStrategy(name string, config Config) (Strategy, error) {
+	strategiesMu.RLock()
+	factory, ok := strategies[name]
+	strategiesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown strategy %q (known: %s)", name, strings.Join(Strategies(), ", "))
+	}
+	return factory(config), nil
+}
+
+// what a strategy knows about the session it scores for
+type State struct {
+	Session    *models.Session
+	Intent     []float64          // zero until the session has preferences
+	Lexical    map[string]float64 // keyword scores of the session query in [0, 1], nil without one
+	Foods      map[string]*models.FoodWithEmbedding
+	Popularity *Popularity
+	Rand       *rand.Rand // seeded per session and card, see sessionRand
+
+	hybrid   HybridWeights
+	position map[string]int // catalog order, for sessions without preferences
+}
+
+// true while the session has no preferences to score against
+func (s *State) Neutral() bool {
+	return IsZeroVector(s.Intent)
+}
+
+// how well a food fits the session: cosine similarity to the intent, blended
+// with the keyword score for sessions seeded with text. before the first
+// preference it falls back to catalog order, first food highest.
+func (s *State) Relevance(food *models.FoodWithEmbedding) float64 {
+	if s.Neutral() {
+		return 1 - float64(s.position[food.ID])/float64(len(s.position)+1)
+	}
+	cosine := CosineSimilarity(s.Intent, food.Embedding)
+	if s.Lexical != nil {
+		return s.hybrid.Blend(cosine, s.Lexical[food.ID])
+	}
+	return cosine
+}
+
+// the last n cards shown to the session that are still in the catalog
+func (s *State) RecentlyShown(n int) []*models.FoodWithEmbedding {
+	var recent []*models.FoodWithEmbedding
+	for _, id := range s.Session.RecentlyShown(n) {
+		if food, ok := s.Foods[id]; ok {
+			recent = append(recent, food)
+		}
+	}
+	return recent
+}
+
+// random source for the session's next pick. derived from the session seed
+// and how many cards it has seen, so a session replays the same way and
+// asking twice for the same card gives the same answer.
+func sessionRand(session *models.Session) *rand.Rand {
+	return rand.New(rand.NewSource(session.Seed + int64(session.SeenCount())*7919))
+}
@@ -0,0 +1,162 @@
+package engine
+
+import (
+	"server2/models"
+	"testing"
+)
+
+// a session that likes {0.8, 0, 0.6} and was just shown "shown"
+func strategyState(seed int64) (*State, []*models.FoodWithEmbedding) {
+	foods := []models.FoodWithEmbedding{
+		{Food: models.Food{ID: "shown"}, Embedding: []float64{1, 0, 0}},
+		{Food: models.Food{ID: "twin"}, Embedding: []float64{0.95, 0.05, 0}},
+		{Food: models.Food{ID: "other"}, Embedding: []float64{0, 0, 1}},
+		{Food: models.Food{ID: "far"}, Embedding: []float64{0, 1, 0}},
+	}
+	session := models.NewSession("test", 3)
+	session.Seed = seed
+	session.MarkSeen("shown")
+
+	state := &State{
+		Session:  session,
+		Intent:   []float64{0.8, 0, 0.6},
+		Foods:    make(map[string]*models.FoodWithEmbedding),
+		Rand:     sessionRand(session),
+		position: make(map[string]int),
+	}
+	var candidates []*models.FoodWithEmbedding
+	for i := range foods {
+		state.Foods[foods[i].ID] = &foods[i]
+		state.position[foods[i].ID] = i
+		if i > 0 {
+			candidates = append(candidates, &foods[i])
+		}
+	}
+	return state, candidates
+}
+
+// the candidate a strategy would show
+func bestID(strategy Strategy, state *State, candidates []*models.FoodWithEmbedding) string {
+	scores := strategy.Score(state, candidates)
+	best := 0
+	for i := range scores {
+		if scores[i] > scores[best] {
+			best = i
+		}
+	}
+	return candidates[best].ID
+}
+
+func TestMMRAvoidsRecentlyShown(t *testing.T) {
+	state, candidates := strategyState(1)
+
+	if got := bestID(cosineStrategy{}, state, candidates); got != "twin" {
+		t.Fatalf("Cosine should pick the closest food, got %s", got)
+	}
+	if got := bestID(mmrStrategy{lambda: 0.5, window: 5}, state, candidates); got != "other" {
+		t.Errorf("MMR should skip the near-copy of the card just shown, got %s", got)
+	}
+}
+
+func TestRandomStrategiesAreSeeded(t *testing.T) {
+	for name, strategy := range map[string]Strategy{
+		StrategyBandit:  banditStrategy{epsilon: 1},
+		StrategySoftmax: softmaxStrategy{temperature: 1},
+	} {
+		picks := map[string]bool{}
+		for seed := int64(0); seed < 20; seed++ {
+			a, candidates := strategyState(seed)
+			b, _ := strategyState(seed)
+
+			first := bestID(strategy, a, candidates)
+			if again := bestID(strategy, b, candidates); again != first {
+				t.Errorf("%s: sessions with seed %d picked %s and %s", name, seed, first, again)
+			}
+			picks[first] = true
+		}
+		if len(picks) < 2 {
+			t.Errorf("%s: expected different seeds to explore different foods, got %v", name, picks)
+		}
+	}
+}
+
+func TestSoftmaxLowTemperatureIsGreedy(t *testing.T) {
+	for seed := int64(0); seed < 10; seed++ {
+		state, candidates := strategyState(seed)
+		if got := bestID(softmaxStrategy{temperature: 0.001}, state, candidates); got != "twin" {
+			t.Errorf("Seed %d: a cold softmax should pick the best food, got %s", seed, got)
+		}
+	}
+}
+
+func TestPopularityStrategy(t *testing.T) {
+	state, candidates := strategyState(1)
+	state.Popularity = NewPopularity()
+	for i := 0; i < 3; i++ {
+		state.Popularity.Record(state.Session.Catalog, "far", "right")
+		state.Popularity.Record(state.Session.Catalog, "twin", "left")
+	}
+
+	if got := bestID(popularityStrategy{}, state, candidates); got != "far" {
+		t.Errorf("The most liked food should come first, got %s", got)
+	}
+	state.Popularity.Record("elsewhere", "other", "super")
+	if state.Popularity.Score(state.Session.Catalog, "other") != 0.5 {
+		t.Error("Swipes in another catalog should not count")
+	}
+}
+
+func TestStrategyRegistry(t *testing.T) {
+	for _, name := range []string{StrategyCosine, StrategyMMR, StrategyBandit, StrategySoftmax, StrategyPopularity} {
+		if _, err := NewStrategy(name, DefaultConfig()); err != nil {
+			t.Errorf("Built-in strategy %s: %v", name, err)
+		}
+	}
+	if _, err := NewStrategy("oracle", DefaultConfig()); err == nil {
+		t.Error("Unknown strategies should be rejected")
+	}
+
+	RegisterStrategy("test-last", func(Config) Strategy { return reverseStrategy{} })
+	defer delete(strategies, "test-last")
+
+	state, candidates := strategyState(1)
+	strategy, err := NewStrategy("test-last", DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := bestID(strategy, state, candidates); got != "far" {
+		t.Errorf("Registered strategy should be used, got %s", got)
+	}
+}
+
+// prefers the last candidate
+type reverseStrategy struct{}
+
+func (reverseStrategy) Score(state *State, candidates []*models.FoodWithEmbedding) []float64 {
+	scores := make([]float64, len(candidates))
+	for i := range scores {
+		scores[i] = float64(i)
+	}
+	return scores
+}
+
+func TestConfigValidate(t *testing.T) {
+	if err := DefaultConfig().Validate(); err != nil {
+		t.Errorf("Defaults should be valid: %v", err)
+	}
+
+	bad := []Config{{Strategy: "oracle", Exploration: DefaultExploration}}
+	for _, e := range []ExplorationConfig{
+		{Lambda: 2, Temperature: 1},
+		{Epsilon: -0.1, Temperature: 1},
+		{Window: -1, Temperature: 1},
+		{},
+	} {
+		bad = append(bad, Config{Exploration: e})
+	}
+	for _, c := range bad {
+		if c.Validate() == nil {
+			t.Errorf("%+v should be rejected", c)
+		}
+	}
+}
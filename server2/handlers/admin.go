@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log"
+	"net/http"
+	"server2/models"
+	"server2/store"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handles the catalog admin routes
+type AdminHandler struct {
+	catalogs *store.Catalogs
+	audit    *store.AuditLog
+	token    string
+}
+
+// creates a new admin handler, requests must send "Authorization: Bearer <token>"
+func NewAdminHandler(catalogs *store.Catalogs, audit *store.AuditLog, token string) *AdminHandler {
+	return &AdminHandler{
+		catalogs: catalogs,
+		audit:    audit,
+		token:    token,
+	}
+}
+
+// the catalog named by ?catalog=, the default one when absent. writes a 404 if unknown.
+func (h *AdminHandler) catalog(c *gin.Context) (*store.FoodStore, string, bool) {
+	id := c.DefaultQuery("catalog", store.DefaultCatalog)
+	foodStore := h.catalogs.Get(id)
+	if foodStore == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "catalog not found"})
+		return nil, "", false
+	}
+	return foodStore, id, true
+}
+
+// rejects requests without "Authorization: Bearer <token>".
+// an empty token disables the api, it would otherwise match an empty bearer.
+func (h *AdminHandler) RequireToken(c *gin.Context) {
+	if h.token == "" {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin api disabled"})
+		return
+	}
+
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid admin token"})
+		return
+	}
+	c.Next()
+}
+
+// handles POST /admin/foods
+func (h *AdminHandler) CreateFood(c *gin.Context) {
+	foodStore, catalogID, ok := h.catalog(c)
+	if !ok {
+		return
+	}
+
+	var food models.Food
+	if err := c.ShouldBindJSON(&food); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	created, err := foodStore.AddFood(food)
+	if err != nil {
+		catalogError(c, err)
+		return
+	}
+
+	h.record(c, store.AuditEntry{Catalog: catalogID, Action: store.AuditCreate, FoodID: created.ID, After: &created})
+	c.JSON(http.StatusCreated, created)
+}
+
+// handles PUT /admin/foods/:id
+func (h *AdminHandler) UpdateFood(c *gin.Context) {
+	foodStore, catalogID, ok := h.catalog(c)
+	if !ok {
+		return
+	}
+	id := c.Param("id")
+
+	var food models.Food
+	if err := c.ShouldBindJSON(&food); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	if food.ID != "" && food.ID != id {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "id in body does not match url"})
+		return
+	}
+
+	before, err := foodStore.UpdateFood(id, food)
+	if err != nil {
+		catalogError(c, err)
+		return
+	}
+
+	food.ID = id
+	h.record(c, store.AuditEntry{Catalog: catalogID, Action: store.AuditUpdate, FoodID: id, Before: &before, After: &food})
+	c.JSON(http.StatusOK, food)
+}
+
+// handles DELETE /admin/foods/:id
+func (h *AdminHandler) DeleteFood(c *gin.Context) {
+	foodStore, catalogID, ok := h.catalog(c)
+	if !ok {
+		return
+	}
+	id := c.Param("id")
+
+	removed, err := foodStore.DeleteFood(id)
+	if err != nil {
+		catalogError(c, err)
+		return
+	}
+
+	h.record(c, store.AuditEntry{Catalog: catalogID, Action: store.AuditDelete, FoodID: id, Before: &removed})
+	c.Status(http.StatusNoContent)
+}
+
+// writes an audit entry for a change that already went through
+func (h *AdminHandler) record(c *gin.Context, entry store.AuditEntry) {
+	// everyone shares the token, so the header is only a claim
+	entry.Actor = "admin"
+	entry.Claimed = c.GetHeader("X-Admin-User")
+	entry.Remote = c.ClientIP()
+
+	if err := h.audit.Record(entry); err != nil {
+		log.Printf("AUDIT WRITE FAILED for %s %s: %v", entry.Action, entry.FoodID, err)
+	}
+}
+
+// maps catalog edit errors to responses
+func catalogError(c *gin.Context, err error) {
+	var invalid models.ValidationErrors
+	switch {
+	case errors.As(err, &invalid):
+		problems := make([]string, len(invalid))
+		for i, fe := range invalid {
+			problems[i] = fe.Field + " " + fe.Reason
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid food", "problems": problems})
+	case errors.Is(err, store.ErrFoodNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, store.ErrFoodExists), errors.Is(err, store.ErrNameTaken), errors.Is(err, store.ErrReadOnly):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, store.ErrNotReady), errors.Is(err, store.ErrLoadFailed), errors.Is(err, store.ErrEmbedderUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	default:
+		log.Printf("Catalog update failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update catalog"})
+	}
+}
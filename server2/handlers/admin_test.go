@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"server2/embedding"
+	"server2/store"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// builds a loaded catalog with the admin routes mounted, returns the audit log path
+func newAdminRouter(t *testing.T) (*gin.Engine, *store.FoodStore, string) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "foods.json")
+	if err := os.WriteFile(path, []byte(testFoods), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	foodStore, err := store.NewFoodStore(path, embedding.NewHashEmbedder(32))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	auditPath := filepath.Join(dir, "audit.log")
+	h := NewAdminHandler(store.NewCatalogs(foodStore), store.NewAuditLog(auditPath), "secret")
+
+	r := gin.New()
+	admin := r.Group("/admin", h.RequireToken)
+	admin.POST("/foods", h.CreateFood)
+	admin.PUT("/foods/:id", h.UpdateFood)
+	admin.DELETE("/foods/:id", h.DeleteFood)
+	return r, foodStore, auditPath
+}
+
+// sends an admin request with the given token and returns the status code
+func adminRequest(r http.Handler, token, method, path, body string) int {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("X-Admin-User", "alice")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w.Code
+}
+
+func doAdminRequest(r http.Handler, method, path, body string) int {
+	return adminRequest(r, "secret", method, path, body)
+}
+
+func TestAdminRequiresToken(t *testing.T) {
+	r, _, _ := newAdminRouter(t)
+
+	if w := doRequest(r, "DELETE", "/admin/foods/1", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("Missing token should be 401, got %d", w.Code)
+	}
+	if code := adminRequest(r, "wrong", "DELETE", "/admin/foods/1", ""); code != http.StatusUnauthorized {
+		t.Errorf("Wrong token should be 401, got %d", code)
+	}
+
+	req := httptest.NewRequest("DELETE", "/admin/foods/1", nil)
+	req.Header.Set("Authorization", "secret")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Token without the Bearer scheme should be 401, got %d", w.Code)
+	}
+}
+
+func TestAdminDisabledWithoutToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := NewAdminHandler(store.NewCatalogs(nil), nil, "")
+	r := gin.New()
+	r.DELETE("/admin/foods/:id", h.RequireToken, h.DeleteFood)
+
+	if code := adminRequest(r, "", "DELETE", "/admin/foods/1", ""); code != http.StatusForbidden {
+		t.Errorf("Empty admin token should disable the api with 403, got %d", code)
+	}
+}
+
+func TestAdminCRUD(t *testing.T) {
+	r, foodStore, auditPath := newAdminRouter(t)
+
+	body := `{"name": "Pad Thai", "description": "Stir-fried rice noodles", "course": "main", "allergens": ["peanuts"]}`
+	if code := doAdminRequest(r, "POST", "/admin/foods", body); code != http.StatusCreated {
+		t.Fatalf("Create should be 201, got %d", code)
+	}
+	if foodStore.GetByName("pad thai") == nil {
+		t.Fatal("Created food should be served")
+	}
+
+	body = `{"name": "Sushi Platter", "description": "Nigiri and maki", "price_range": 3}`
+	if code := doAdminRequest(r, "PUT", "/admin/foods/2", body); code != http.StatusOK {
+		t.Errorf("Update should be 200, got %d", code)
+	}
+	if code := doAdminRequest(r, "PUT", "/admin/foods/2", `{"id": "3", "name": "Sushi Platter"}`); code != http.StatusBadRequest {
+		t.Errorf("Mismatched id should be 400, got %d", code)
+	}
+	if code := doAdminRequest(r, "POST", "/admin/foods", `{"name": "Soup", "spice_level": 9}`); code != http.StatusBadRequest {
+		t.Errorf("Invalid food should be 400, got %d", code)
+	}
+	if code := doAdminRequest(r, "POST", "/admin/foods", `{"name": "paneer tikka", "description": "Another grilled paneer dish"}`); code != http.StatusConflict {
+		t.Errorf("Duplicate name should be 409, got %d", code)
+	}
+	if code := doAdminRequest(r, "DELETE", "/admin/foods/1", ""); code != http.StatusNoContent {
+		t.Errorf("Delete should be 204, got %d", code)
+	}
+	if code := doAdminRequest(r, "DELETE", "/admin/foods/1", ""); code != http.StatusNotFound {
+		t.Errorf("Deleting twice should be 404, got %d", code)
+	}
+
+	data, err := os.ReadFile(auditPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected one audit entry per change, got %d:\n%s", len(lines), data)
+	}
+	var entry store.AuditEntry
+	if err := json.Unmarshal([]byte(lines[1]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Action != store.AuditUpdate || entry.Actor != "admin" || entry.Claimed != "alice" || entry.FoodID != "2" ||
+		entry.Before == nil || entry.Before.Description != "Rice and raw fish" || entry.After.PriceRange != 3 {
+		t.Errorf("Unexpected audit entry: %s", lines[1])
+	}
+}
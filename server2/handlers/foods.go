@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"server2/models"
+	"server2/search"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// page sizes for /foods
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// handles GET /foods: lists a catalog page by page, with attribute filters
+// and an optional fuzzy name search (q)
+func (h *Handler) ListFoods(c *gin.Context) {
+	foodStore, ok := h.requestCatalog(c)
+	if !ok {
+		return
+	}
+
+	filter, err := parseFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	page, err := queryInt(c, "page", 1, 1, 1<<20)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	pageSize, err := queryInt(c, "page_size", defaultPageSize, 1, maxPageSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	type match struct {
+		food  *models.FoodWithEmbedding
+		score float64
+	}
+	query := c.Query("q")
+	foods := foodStore.GetAll()
+	matches := make([]match, 0, len(foods))
+	for i := range foods {
+		if !filter.Matches(&foods[i].Food) {
+			continue
+		}
+		m := match{food: &foods[i]}
+		if query != "" {
+			if m.score = search.FoodNameScore(query, &foods[i].Food); m.score < search.FuzzyThreshold {
+				continue
+			}
+		}
+		matches = append(matches, m)
+	}
+	// catalog order unless searching, then best match first
+	if query != "" {
+		sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+	}
+
+	start := (page - 1) * pageSize
+	end := start + pageSize
+	if start > len(matches) {
+		start = len(matches)
+	}
+	if end > len(matches) {
+		end = len(matches)
+	}
+
+	items := make([]gin.H, 0, end-start)
+	for _, m := range matches[start:end] {
+		item := foodDetail(m.food)
+		if query != "" {
+			item["score"] = m.score
+		}
+		items = append(items, item)
+	}
+
+	resp := gin.H{
+		"foods":     items,
+		"total":     len(matches),
+		"page":      page,
+		"page_size": pageSize,
+	}
+	if end < len(matches) {
+		resp["next_page"] = page + 1
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// handles GET /foods/:id, answers 304 when the client's ETag is current
+func (h *Handler) GetFood(c *gin.Context) {
+	foodStore, ok := h.requestCatalog(c)
+	if !ok {
+		return
+	}
+
+	food := foodStore.GetByID(c.Param("id"))
+	if food == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "food not found"})
+		return
+	}
+
+	etag := foodETag(&food.Food)
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "no-cache") // cache, but revalidate: admin edits and reloads change foods
+	if match := c.GetHeader("If-None-Match"); match != "" && etagMatches(match, etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.JSON(http.StatusOK, foodDetail(food))
+}
+
+// every public field of a food, including id and nutrition
+func foodDetail(food *models.FoodWithEmbedding) gin.H {
+	resp := foodFields(&food.Food)
+	resp["id"] = food.ID
+	resp["nutrition"] = food.Nutrients
+	return resp
+}
+
+// strong ETag over the food's content, changes whenever any field does
+func foodETag(food *models.Food) string {
+	data, _ := json.Marshal(food)
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// true if an If-None-Match header lists the etag (or is *)
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// reads attribute filters from the query string:
+// cuisine, course, tag, vegetarian, vegan, gluten_free, halal, no_nuts,
+// exclude_allergens (comma separated), max_spice_level, max_price_range
+func parseFilter(c *gin.Context) (models.Filter, error) {
+	f := models.Filter{
+		Cuisine: c.Query("cuisine"),
+		Course:  c.Query("course"),
+		Tag:     c.Query("tag"),
+	}
+
+	flags := map[string]*bool{
+		"vegetarian":  &f.Vegetarian,
+		"vegan":       &f.Vegan,
+		"gluten_free": &f.GlutenFree,
+		"halal":       &f.Halal,
+		"no_nuts":     &f.NoNuts,
+	}
+	for name, dst := range flags {
+		if v := c.Query(name); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return f, fmt.Errorf("%s must be true or false", name)
+			}
+			*dst = b
+		}
+	}
+
+	if v := c.Query("exclude_allergens"); v != "" {
+		for _, a := range strings.Split(v, ",") {
+			if a = strings.TrimSpace(a); a != "" {
+				f.ExcludeAllergens = append(f.ExcludeAllergens, a)
+			}
+		}
+	}
+	for name, dst := range map[string]**int{
+		"max_spice_level": &f.MaxSpiceLevel,
+		"max_price_range": &f.MaxPriceRange,
+	} {
+		if v := c.Query(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return f, fmt.Errorf("%s must be a number", name)
+			}
+			*dst = &n
+		}
+	}
+
+	return f, f.Validate()
+}
+
+// reads an optional integer query parameter within [min, max]
+func queryInt(c *gin.Context, name string, fallback, min, max int) (int, error) {
+	v := c.Query(name)
+	if v == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < min || n > max {
+		return 0, fmt.Errorf("%s must be between %d and %d", name, min, max)
+	}
+	return n, nil
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type foodsPage struct {
+	Foods []struct {
+		ID    string  `json:"id"`
+		Name  string  `json:"name"`
+		Score float64 `json:"score"`
+	} `json:"foods"`
+	Total    int `json:"total"`
+	NextPage int `json:"next_page"`
+}
+
+func listFoods(t *testing.T, r http.Handler, query string) foodsPage {
+	t.Helper()
+	w := doRequest(r, "GET", "/foods"+query, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /foods%s = %d: %s", query, w.Code, w.Body)
+	}
+	var page foodsPage
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+		t.Fatal(err)
+	}
+	return page
+}
+
+func TestListFoodsPagination(t *testing.T) {
+	r := newLoadedRouter(t)
+
+	first := listFoods(t, r, "?page_size=2")
+	if first.Total != 3 || len(first.Foods) != 2 || first.NextPage != 2 {
+		t.Errorf("Unexpected first page: %+v", first)
+	}
+	second := listFoods(t, r, "?page_size=2&page=2")
+	if len(second.Foods) != 1 || second.Foods[0].Name != "Paneer Tikka" || second.NextPage != 0 {
+		t.Errorf("Unexpected last page: %+v", second)
+	}
+	if past := listFoods(t, r, "?page=9"); len(past.Foods) != 0 || past.Total != 3 {
+		t.Errorf("Pages past the end should be empty: %+v", past)
+	}
+}
+
+func TestListFoodsFilters(t *testing.T) {
+	r := newLoadedRouter(t)
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"?vegetarian=true", []string{"Paneer Tikka"}},
+		{"?exclude_allergens=dairy", []string{"Sushi Platter"}},
+		{"?max_spice_level=2&gluten_free=true", []string{"Butter Chicken"}},
+		{"?q=panner+tika", []string{"Paneer Tikka"}},
+		{"?q=chicken&vegetarian=true", nil},
+	}
+	for _, tt := range tests {
+		page := listFoods(t, r, tt.query)
+		var names []string
+		for _, f := range page.Foods {
+			names = append(names, f.Name)
+		}
+		if len(names) != len(tt.want) || (len(names) > 0 && names[0] != tt.want[0]) {
+			t.Errorf("GET /foods%s = %v, want %v", tt.query, names, tt.want)
+		}
+	}
+
+	for _, query := range []string{"?page_size=500", "?vegan=maybe", "?course=brunch", "?exclude_allergens=celery"} {
+		if w := doRequest(r, "GET", "/foods"+query, ""); w.Code != http.StatusBadRequest {
+			t.Errorf("GET /foods%s should be 400, got %d", query, w.Code)
+		}
+	}
+}
+
+func TestGetFoodETag(t *testing.T) {
+	r := newLoadedRouter(t)
+
+	w := doRequest(r, "GET", "/foods/3", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", w.Code)
+	}
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("Response should carry an ETag")
+	}
+
+	req := httptest.NewRequest("GET", "/foods/3", nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("Matching If-None-Match should be 304 with no body, got %d", w.Code)
+	}
+
+	if w := doRequest(r, "GET", "/foods/2", ""); w.Header().Get("ETag") == etag {
+		t.Error("Different foods should have different ETags")
+	}
+	if w := doRequest(r, "GET", "/foods/99", ""); w.Code != http.StatusNotFound {
+		t.Errorf("Unknown food should be 404, got %d", w.Code)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	r := newLoadedRouter(t)
+
+	w := doRequest(r, "GET", "/search?q=grilled+cottage+cheese&k=1", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body)
+	}
+	var body struct {
+		Results []struct {
+			Name  string  `json:"name"`
+			Score float64 `json:"score"`
+		} `json:"results"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if len(body.Results) != 1 || body.Results[0].Name != "Paneer Tikka" || body.Results[0].Score <= 0 {
+		t.Errorf("Unexpected results: %s", w.Body)
+	}
+
+	w = doRequest(r, "GET", "/search?q=grilled+cottage+cheese&exclude_allergens=dairy", "")
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if len(body.Results) != 1 || body.Results[0].Name != "Sushi Platter" {
+		t.Errorf("Filters should apply to search: %s", w.Body)
+	}
+
+	for _, query := range []string{"", "?q=", "?q=curry&k=0", "?q=curry&k=51"} {
+		if w := doRequest(r, "GET", "/search"+query, ""); w.Code != http.StatusBadRequest {
+			t.Errorf("GET /search%s should be 400, got %d", query, w.Code)
+		}
+	}
+}
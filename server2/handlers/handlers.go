@@ -1,33 +1,116 @@
 package handlers
 
 import (
+	"errors"
+	"io"
+	"log"
 	"net/http"
 	"server2/engine"
+	"server2/models"
 	"server2/store"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
 type Handler struct {
-	foodStore    *store.FoodStore
+	catalogs     *store.Catalogs
 	sessionStore *store.SessionStore
 	recommender  *engine.Recommender
+	version      string
 }
 
 // creates a new handler
-func NewHandler(foodStore *store.FoodStore, sessionStore *store.SessionStore, recommender *engine.Recommender) *Handler {
+func NewHandler(catalogs *store.Catalogs, sessionStore *store.SessionStore, recommender *engine.Recommender, version string) *Handler {
 	return &Handler{
-		foodStore:    foodStore,
+		catalogs:     catalogs,
 		sessionStore: sessionStore,
 		recommender:  recommender,
+		version:      version,
 	}
 }
 
+// request body for session, all fields optional
+type CreateSessionRequest struct {
+	Catalog     string             `json:"catalog"` // catalog ID, default catalog when empty
+	Constraints models.Constraints `json:"constraints"`
+	Query       string             `json:"query"`    // free text to start from, e.g. "something with paneer"
+	Seed        *int64             `json:"seed"`     // fixes random exploration, for reproducible sessions
+	Strategy    string             `json:"strategy"` // recommendation strategy, deployment default when empty
+}
+
 // handles /session
 func (h *Handler) CreateSession(c *gin.Context) {
-	sessionID := h.sessionStore.Create()
+	var req CreateSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	if err := req.Constraints.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Strategy != "" && !engine.HasStrategy(req.Strategy) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown strategy", "strategies": engine.Strategies()})
+		return
+	}
+
+	foodStore := h.catalogs.Get(req.Catalog)
+	if foodStore == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "catalog not found"})
+		return
+	}
+	if err := foodStore.LoadError(); err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	if !foodStore.Ready() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "catalog is still loading"})
+		return
+	}
+
+	// refuse sessions that could never show a single card
+	candidates := 0
+	foods := foodStore.GetAll()
+	for i := range foods {
+		if req.Constraints.Allows(&foods[i].Food) {
+			candidates++
+		}
+	}
+	if candidates == 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "no foods match these constraints"})
+		return
+	}
+
+	sessionID, err := h.sessionStore.Create(store.SessionOptions{
+		Catalog:     req.Catalog,
+		Constraints: req.Constraints,
+		Query:       strings.TrimSpace(req.Query),
+		Seed:        req.Seed,
+		Strategy:    req.Strategy,
+	})
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "catalog not found"})
+		return
+	}
+	session := h.sessionStore.Get(sessionID)
+	if session.Query != "" {
+		if err := h.recommender.SeedIntent(session); err != nil {
+			log.Printf("Failed to seed session: %v", err)
+			h.sessionStore.Delete(sessionID)
+			c.JSON(http.StatusBadGateway, gin.H{"error": "embedding provider unavailable"})
+			return
+		}
+	}
 	c.JSON(http.StatusOK, gin.H{
 		"session_id": sessionID,
+		"catalog":    session.Catalog,
+		"candidates": candidates,
+		"seed":       session.Seed,
+		"strategy":   h.recommender.StrategyFor(session),
 	})
 }
 
@@ -58,19 +141,51 @@ func (h *Handler) GetRecommendation(c *gin.Context) {
 
 	session.MarkSeen(food.ID)
 
-	c.JSON(http.StatusOK, gin.H{
-		"name":        food.Name,
-		"description": food.Description,
-	})
+	resp := foodFields(&food.Food)
+	resp["id"] = food.ID
+	c.JSON(http.StatusOK, resp)
+}
+
+// public fields of a food, shared by the food endpoints
+func foodFields(food *models.Food) gin.H {
+	return gin.H{
+		"name":              food.Name,
+		"aliases":           food.Aliases,
+		"description":       food.Description,
+		"cuisine":           food.Cuisine,
+		"course":            food.Course,
+		"tags":              food.Tags,
+		"vegetarian":        food.Vegetarian,
+		"vegan":             food.Vegan,
+		"gluten_free":       food.GlutenFree,
+		"halal":             food.Halal,
+		"allergens":         food.Allergens,
+		"spice_level":       food.SpiceLevel,
+		"price_range":       food.PriceRange,
+		"prep_time_minutes": food.PrepTime,
+		"image_url":         food.ImageURL,
+	}
 }
 
-//request body for swipe
+// request body for swipe, food_id is preferred, food_name is kept for older clients
 type SwipeRequest struct {
 	SessionID string `json:"session_id"`
+	FoodID    string `json:"food_id"`
 	FoodName  string `json:"food_name"`
 	Action    string `json:"action"`
 }
 
+// looks a food up in a catalog by ID, falling back to its name or alias
+func findFood(foodStore *store.FoodStore, id, name string) *store.FoodWithEmbedding {
+	if id != "" {
+		return foodStore.GetByID(id)
+	}
+	if name != "" {
+		return foodStore.GetByName(name)
+	}
+	return nil
+}
+
 // handles /swipe
 func (h *Handler) Swipe(c *gin.Context) {
 	var req SwipeRequest
@@ -95,7 +210,19 @@ func (h *Handler) Swipe(c *gin.Context) {
 		return
 	}
 
-	food := h.foodStore.GetByName(req.FoodName)
+	if req.FoodID == "" && req.FoodName == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "food_id or food_name required"})
+		return
+	}
+
+	// foods are resolved in the session's own catalog
+	foodStore := h.catalogs.Get(session.Catalog)
+	if foodStore == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "catalog not found"})
+		return
+	}
+
+	food := findFood(foodStore, req.FoodID, req.FoodName)
 	if food == nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "food not found"})
 		return
@@ -104,8 +231,77 @@ func (h *Handler) Swipe(c *gin.Context) {
 	h.recommender.UpdateIntent(session, food, req.Action)
 
 	if req.Action == "super" {
-		session.Complete(req.FoodName)
+		session.Complete(food.Name)
 	}
 
 	c.JSON(http.StatusOK, gin.H{"status": "ok"})
 }
+
+// the catalog a read request is about: the session's catalog when session_id is
+// given, else the catalog parameter, else the default. writes a 404 if unknown.
+func (h *Handler) requestCatalog(c *gin.Context) (*store.FoodStore, bool) {
+	catalogID := c.Query("catalog")
+	if sessionID := c.Query("session_id"); sessionID != "" {
+		session := h.sessionStore.Get(sessionID)
+		if session == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+			return nil, false
+		}
+		catalogID = session.Catalog
+	}
+	foodStore := h.catalogs.Get(catalogID)
+	if foodStore == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "catalog not found"})
+		return nil, false
+	}
+	return foodStore, true
+}
+
+// number of similar foods returned by /food-info
+const defaultSimilarFoods = 5
+
+// handles /food-info
+func (h *Handler) FoodInfo(c *gin.Context) {
+	id, name := c.Query("food_id"), c.Query("food_name")
+	if id == "" && name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "food_id or food_name required"})
+		return
+	}
+
+	k := defaultSimilarFoods
+	if v := c.Query("k"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || n > 50 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "k must be between 0 and 50"})
+			return
+		}
+		k = n
+	}
+
+	foodStore, ok := h.requestCatalog(c)
+	if !ok {
+		return
+	}
+
+	food := findFood(foodStore, id, name)
+	if food == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "food not found"})
+		return
+	}
+
+	similar := h.recommender.SimilarFoods(foodStore, food, k)
+	names := make([]string, len(similar))
+	details := make([]gin.H, len(similar))
+	for i, s := range similar {
+		names[i] = s.Food.Name
+		details[i] = gin.H{"id": s.Food.ID, "name": s.Food.Name, "score": s.Score}
+	}
+
+	resp := foodFields(&food.Food)
+	resp["id"] = food.ID
+	resp["nutrients"] = food.Nutrients.Summary()
+	resp["nutrition"] = food.Nutrients
+	resp["similar_foods"] = names
+	resp["similar"] = details
+	c.JSON(http.StatusOK, resp)
+}
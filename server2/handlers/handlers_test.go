@@ -0,0 +1,373 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"server2/embedding"
+	"server2/engine"
+	"server2/store"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+const testFoods = `[
+	{"id": "1", "name": "Butter Chicken", "description": "Creamy North Indian curry",
+	 "gluten_free": true, "allergens": ["dairy"], "spice_level": 2, "price_range": 2,
+	 "nutrients": {"calories": 490, "protein_g": 32, "carbs_g": 14, "fat_g": 34, "fiber_g": 3}},
+	{"id": "2", "name": "Sushi Platter", "description": "Rice and raw fish",
+	 "allergens": ["fish", "soy"], "spice_level": 0, "price_range": 4},
+	{"id": "3", "name": "Paneer Tikka", "description": "Spiced cottage cheese skewers",
+	 "vegetarian": true, "halal": true, "gluten_free": true, "allergens": ["dairy"], "spice_level": 3, "price_range": 2}
+]`
+
+// catalog for similarity tests: the curries share most of their words
+const similarFoods = `[
+	{"id": "1", "name": "Butter Chicken", "description": "Creamy North Indian curry",
+	 "nutrients": {"calories": 490, "protein_g": 32, "carbs_g": 14, "fat_g": 34, "fiber_g": 3}},
+	{"id": "2", "name": "Sushi Platter", "description": "Rice and raw fish"},
+	{"id": "3", "name": "Chicken Korma", "description": "Creamy North Indian curry with cashews"}
+]`
+
+// builds a router like main.go over an unloaded catalog
+func newTestRouter(t *testing.T) (*gin.Engine, *store.FoodStore) {
+	return newTestRouterWith(t, testFoods)
+}
+
+// builds a router like main.go over an unloaded catalog of the given foods
+func newTestRouterWith(t *testing.T, foods string) (*gin.Engine, *store.FoodStore) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	path := filepath.Join(t.TempDir(), "foods.json")
+	if err := os.WriteFile(path, []byte(foods), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	foodStore := store.OpenFoodStore(path, embedding.NewHashEmbedder(32))
+	return newRouter(store.NewCatalogs(foodStore)), foodStore
+}
+
+// builds the public routes over a set of catalogs
+func newRouter(catalogs *store.Catalogs) *gin.Engine {
+	sessionStore := store.NewSessionStore(catalogs)
+	h := NewHandler(catalogs, sessionStore, engine.NewRecommender(catalogs), "test")
+
+	r := gin.New()
+	r.GET("/healthz", h.Healthz)
+	r.GET("/readyz", h.Readyz)
+	api := r.Group("/", h.RequireReady)
+	api.POST("/session", h.CreateSession)
+	api.GET("/recommendation", h.GetRecommendation)
+	api.POST("/swipe", h.Swipe)
+	api.GET("/food-info", h.FoodInfo)
+	api.GET("/foods", h.ListFoods)
+	api.GET("/foods/:id", h.GetFood)
+	api.GET("/search", h.Search)
+	return r
+}
+
+func doRequest(r http.Handler, method, path string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// router over a loaded catalog
+func newLoadedRouter(t *testing.T) *gin.Engine {
+	return newLoadedRouterWith(t, testFoods)
+}
+
+// router over a loaded catalog of the given foods
+func newLoadedRouterWith(t *testing.T, foods string) *gin.Engine {
+	t.Helper()
+	r, foodStore := newTestRouterWith(t, foods)
+	if err := foodStore.Load(); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestFoodInfo(t *testing.T) {
+	r := newLoadedRouterWith(t, similarFoods)
+
+	w := doRequest(r, "GET", "/food-info?food_name=Butter+Chicken&k=2", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body)
+	}
+
+	var body struct {
+		Description  string   `json:"description"`
+		Nutrients    string   `json:"nutrients"`
+		SimilarFoods []string `json:"similar_foods"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Description != "Creamy North Indian curry" {
+		t.Errorf("Unexpected description %q", body.Description)
+	}
+	if !strings.Contains(body.Nutrients, "490 kcal") {
+		t.Errorf("Unexpected nutrients %q", body.Nutrients)
+	}
+	if len(body.SimilarFoods) != 2 || body.SimilarFoods[0] != "Chicken Korma" {
+		t.Errorf("Expected Chicken Korma as the closest of 2 similar foods, got %v", body.SimilarFoods)
+	}
+}
+
+func TestFoodInfoErrors(t *testing.T) {
+	r := newLoadedRouter(t)
+
+	if w := doRequest(r, "GET", "/food-info", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("Missing food_name should be 400, got %d", w.Code)
+	}
+	if w := doRequest(r, "GET", "/food-info?food_name=Nope", ""); w.Code != http.StatusNotFound {
+		t.Errorf("Unknown food should be 404, got %d", w.Code)
+	}
+	if w := doRequest(r, "GET", "/food-info?food_name=Sushi+Platter&k=-1", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("Negative k should be 400, got %d", w.Code)
+	}
+}
+
+// creates a session and returns its id
+func createSession(t *testing.T, r http.Handler, body string) string {
+	t.Helper()
+	w := doRequest(r, "POST", "/session", body)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Creating session failed with %d: %s", w.Code, w.Body)
+	}
+	var resp struct {
+		SessionID string `json:"session_id"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return resp.SessionID
+}
+
+// returns the name of the next recommendation, or "" when there is none
+func nextFood(t *testing.T, r http.Handler, sessionID string) string {
+	t.Helper()
+	w := doRequest(r, "GET", "/recommendation?session_id="+sessionID, "")
+	if w.Code == http.StatusNotFound {
+		return ""
+	}
+	var resp struct {
+		Name string `json:"name"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return resp.Name
+}
+
+func TestSessionConstraintsFilterRecommendations(t *testing.T) {
+	r := newLoadedRouter(t)
+	id := createSession(t, r, `{"constraints": {"vegetarian": true}}`)
+
+	if got := nextFood(t, r, id); got != "Paneer Tikka" {
+		t.Errorf("Only Paneer Tikka is vegetarian, got %q", got)
+	}
+	if got := nextFood(t, r, id); got != "" {
+		t.Errorf("Expected no more recommendations, got %q", got)
+	}
+}
+
+func TestSessionConstraintsSpiceAndPrice(t *testing.T) {
+	r := newLoadedRouter(t)
+	id := createSession(t, r, `{"constraints": {"max_spice_level": 2, "max_price_range": 3, "exclude_allergens": ["fish"]}}`)
+
+	seen := map[string]bool{}
+	for name := nextFood(t, r, id); name != ""; name = nextFood(t, r, id) {
+		seen[name] = true
+	}
+	if len(seen) != 1 || !seen["Butter Chicken"] {
+		t.Errorf("Expected only Butter Chicken, got %v", seen)
+	}
+}
+
+func TestSessionConstraintsErrors(t *testing.T) {
+	r := newLoadedRouter(t)
+
+	if w := doRequest(r, "POST", "/session", `{"constraints": {"vegan": true}}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("No vegan foods should give 422, got %d", w.Code)
+	}
+	if w := doRequest(r, "POST", "/session", `{"constraints": {"max_spice_level": 11}}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Out of range spice should give 400, got %d", w.Code)
+	}
+	if w := doRequest(r, "POST", "/session", `{"constraints": `); w.Code != http.StatusBadRequest {
+		t.Errorf("Broken json should give 400, got %d", w.Code)
+	}
+}
+
+func TestSwipeByIDAndName(t *testing.T) {
+	r := newLoadedRouter(t)
+	id := createSession(t, r, "")
+
+	w := doRequest(r, "GET", "/recommendation?session_id="+id, "")
+	var rec struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &rec)
+	if rec.ID == "" {
+		t.Fatalf("Recommendation should include the food id: %s", w.Body)
+	}
+
+	body := `{"session_id": "` + id + `", "food_id": "` + rec.ID + `", "action": "right"}`
+	if w := doRequest(r, "POST", "/swipe", body); w.Code != http.StatusOK {
+		t.Errorf("Swipe by id failed with %d: %s", w.Code, w.Body)
+	}
+
+	body = `{"session_id": "` + id + `", "food_name": "paneer tikka", "action": "left"}`
+	if w := doRequest(r, "POST", "/swipe", body); w.Code != http.StatusOK {
+		t.Errorf("Swipe by case-insensitive name failed with %d: %s", w.Code, w.Body)
+	}
+
+	body = `{"session_id": "` + id + `", "food_id": "99", "food_name": "Paneer Tikka", "action": "left"}`
+	if w := doRequest(r, "POST", "/swipe", body); w.Code != http.StatusNotFound {
+		t.Errorf("Unknown food_id should be 404 even with a valid name, got %d", w.Code)
+	}
+
+	body = `{"session_id": "` + id + `", "action": "left"}`
+	if w := doRequest(r, "POST", "/swipe", body); w.Code != http.StatusBadRequest {
+		t.Errorf("Swipe without food should be 400, got %d", w.Code)
+	}
+}
+
+func TestSessionsUseTheirCatalog(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	dir := t.TempDir()
+
+	open := func(name, content string) *store.FoodStore {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return store.OpenFoodStore(path, embedding.NewHashEmbedder(32))
+	}
+	defaultStore := open("foods.json", testFoods)
+	cafe := open("cafe-42.json", `[
+		{"id": "1", "name": "Guacamole", "description": "Avocado mashed with lime and cilantro"},
+		{"id": "2", "name": "Al Pastor Tacos", "description": "Spit-roasted pork with pineapple"}
+	]`)
+	pending := open("pending.json", testFoods)
+	broken := open("broken.json", `[{"id": "1", "name": ""}]`)
+
+	catalogs := store.NewCatalogs(defaultStore)
+	catalogs.Add("cafe-42", cafe)
+	catalogs.Add("pending", pending)
+	catalogs.Add("broken", broken)
+	if broken.Load() == nil {
+		t.Fatal("Invalid catalog should fail to load")
+	}
+	for _, s := range []*store.FoodStore{defaultStore, cafe} {
+		if err := s.Load(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := newRouter(catalogs)
+
+	id := createSession(t, r, `{"catalog": "cafe-42"}`)
+	seen := map[string]bool{}
+	for name := nextFood(t, r, id); name != ""; name = nextFood(t, r, id) {
+		seen[name] = true
+	}
+	if len(seen) != 2 || !seen["Guacamole"] || !seen["Al Pastor Tacos"] {
+		t.Errorf("Session should only see the cafe menu, saw %v", seen)
+	}
+
+	// food ids are resolved in the session's catalog, "1" is Butter Chicken in the default one
+	body := `{"session_id": "` + id + `", "food_id": "1", "action": "super"}`
+	if w := doRequest(r, "POST", "/swipe", body); w.Code != http.StatusOK {
+		t.Fatalf("Swipe failed with %d: %s", w.Code, w.Body)
+	}
+	w := doRequest(r, "GET", "/food-info?session_id="+id+"&food_id=1", "")
+	if !strings.Contains(w.Body.String(), "Guacamole") {
+		t.Errorf("food-info should use the session's catalog: %s", w.Body)
+	}
+	w = doRequest(r, "GET", "/food-info?catalog=cafe-42&food_name=al+pastor+tacos", "")
+	if w.Code != http.StatusOK {
+		t.Errorf("food-info by catalog failed with %d", w.Code)
+	}
+
+	if w := doRequest(r, "POST", "/session", `{"catalog": "nope"}`); w.Code != http.StatusNotFound {
+		t.Errorf("Unknown catalog should be 404, got %d", w.Code)
+	}
+	if w := doRequest(r, "POST", "/session", `{"catalog": "pending"}`); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Catalog still loading should be 503, got %d", w.Code)
+	}
+	w = doRequest(r, "POST", "/session", `{"catalog": "broken"}`)
+	if w.Code != http.StatusServiceUnavailable || !strings.Contains(w.Body.String(), "catalog failed to load") {
+		t.Errorf("Broken catalog should be 503 with the load error, got %d: %s", w.Code, w.Body)
+	}
+	if status := broken.Status(); status.Ready || status.LoadError == "" {
+		t.Errorf("Status should report the load error, got %+v", status)
+	}
+	if got := nextFood(t, r, createSession(t, r, "")); got == "Guacamole" || got == "Al Pastor Tacos" {
+		t.Errorf("Default sessions should not see the cafe menu, got %q", got)
+	}
+}
+
+func TestSessionSeededWithQuery(t *testing.T) {
+	r := newLoadedRouter(t)
+
+	if got := nextFood(t, r, createSession(t, r, `{"query": "something with paneer"}`)); got != "Paneer Tikka" {
+		t.Errorf("A paneer query should start with Paneer Tikka, got %q", got)
+	}
+}
+
+func TestSessionStrategy(t *testing.T) {
+	r := newLoadedRouter(t)
+
+	if w := doRequest(r, "POST", "/session", `{"strategy": "oracle"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Unknown strategy should give 400, got %d", w.Code)
+	}
+
+	w := doRequest(r, "POST", "/session", `{"strategy": "mmr", "seed": 7}`)
+	var resp struct {
+		Strategy string `json:"strategy"`
+		Seed     int64  `json:"seed"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Strategy != "mmr" || resp.Seed != 7 {
+		t.Errorf("Session should echo its strategy and seed: %s", w.Body)
+	}
+
+	w = doRequest(r, "POST", "/session", "")
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Strategy != "cosine" {
+		t.Errorf("Sessions without a strategy should use the default, got %q", resp.Strategy)
+	}
+}
+
+func TestColdStartEndsWithFirstPreference(t *testing.T) {
+	r := newLoadedRouterWith(t, similarFoods)
+	curries := map[string]string{"Butter Chicken": "Chicken Korma", "Chicken Korma": "Butter Chicken"}
+
+	liked := 0
+	for seed := 0; seed < 10; seed++ {
+		id := createSession(t, r, fmt.Sprintf(`{"seed": %d}`, seed))
+		first := nextFood(t, r, id)
+		other, ok := curries[first]
+		if !ok {
+			continue
+		}
+		liked++
+		body := `{"session_id": "` + id + `", "food_name": "` + first + `", "action": "right"}`
+		if w := doRequest(r, "POST", "/swipe", body); w.Code != http.StatusOK {
+			t.Fatalf("Swipe failed with %d: %s", w.Code, w.Body)
+		}
+		if got := nextFood(t, r, id); got != other {
+			t.Errorf("Seed %d: after liking %s the next card should be %s, got %q", seed, first, other, got)
+		}
+	}
+	if liked == 0 {
+		t.Fatal("No seed started with a curry")
+	}
+}
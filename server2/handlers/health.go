@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// body of /healthz and /readyz
+func (h *Handler) healthBody() gin.H {
+	status := h.catalogs.Default().Status()
+	catalogs := gin.H{}
+	for _, id := range h.catalogs.IDs() {
+		catalogs[id] = h.catalogs.Get(id).Status()
+	}
+	total, completed := h.sessionStore.Counts()
+
+	state := "starting"
+	if status.Ready {
+		state = "ready"
+	}
+
+	return gin.H{
+		"status":   state,
+		"version":  h.version,
+		"mode":     status.Mode,
+		"catalog":  status,
+		"catalogs": catalogs,
+		"sessions": gin.H{
+			"total":     total,
+			"active":    total - completed,
+			"completed": completed,
+		},
+	}
+}
+
+// handles /healthz (liveness), 200 as long as the process is serving
+func (h *Handler) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, h.healthBody())
+}
+
+// handles /readyz, 503 until the default catalog is loaded
+func (h *Handler) Readyz(c *gin.Context) {
+	code := http.StatusOK
+	if !h.catalogs.Default().Ready() {
+		code = http.StatusServiceUnavailable
+	}
+	c.JSON(code, h.healthBody())
+}
+
+// middleware that rejects requests with 503 until the default catalog is loaded,
+// other catalogs are checked when a session is created on them
+func (h *Handler) RequireReady(c *gin.Context) {
+	if !h.catalogs.Default().Ready() {
+		status := h.catalogs.Default().Status()
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+			"error":    "catalog is still loading",
+			"foods":    status.Foods,
+			"embedded": status.Embedded,
+		})
+		return
+	}
+	c.Next()
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"server2/store"
+	"testing"
+)
+
+func TestReadinessBeforeAndAfterLoad(t *testing.T) {
+	r, foodStore := newTestRouter(t)
+
+	if w := doRequest(r, "GET", "/healthz", ""); w.Code != http.StatusOK {
+		t.Errorf("/healthz should be 200 while starting, got %d", w.Code)
+	}
+	if w := doRequest(r, "GET", "/readyz", ""); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("/readyz should be 503 while starting, got %d", w.Code)
+	}
+	if w := doRequest(r, "POST", "/session", ""); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("/session should be 503 while starting, got %d", w.Code)
+	}
+
+	if err := foodStore.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	w := doRequest(r, "GET", "/readyz", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("/readyz should be 200 once loaded, got %d", w.Code)
+	}
+
+	var body struct {
+		Status   string           `json:"status"`
+		Version  string           `json:"version"`
+		Catalog  store.LoadStatus `json:"catalog"`
+		Sessions map[string]int   `json:"sessions"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Status != "ready" || body.Version != "test" {
+		t.Errorf("Unexpected status %q / version %q", body.Status, body.Version)
+	}
+	if body.Catalog.Foods != 3 || body.Catalog.Embedded != 3 || body.Catalog.Provider != store.ProviderOK {
+		t.Errorf("Unexpected catalog status %+v", body.Catalog)
+	}
+
+	if w := doRequest(r, "POST", "/session", ""); w.Code != http.StatusOK {
+		t.Errorf("/session should work once loaded, got %d", w.Code)
+	}
+	w = doRequest(r, "GET", "/healthz", "")
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if body.Sessions["total"] != 1 || body.Sessions["active"] != 1 {
+		t.Errorf("Unexpected session counts %v", body.Sessions)
+	}
+}
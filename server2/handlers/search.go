@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"server2/search"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handles GET /search: free-text search ranked by embedding similarity
+// blended with BM25 keyword matches, with the same attribute filters as /foods
+func (h *Handler) Search(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q required"})
+		return
+	}
+
+	foodStore, ok := h.requestCatalog(c)
+	if !ok {
+		return
+	}
+	filter, err := parseFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	k, err := queryInt(c, "k", search.DefaultK, 1, search.MaxK)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	results, err := search.Hybrid(foodStore, query, search.Options{
+		K:       k,
+		Filter:  filter,
+		Weights: h.recommender.Config().Hybrid,
+	})
+	if err != nil {
+		log.Printf("Search failed: %v", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "embedding provider unavailable"})
+		return
+	}
+
+	items := make([]gin.H, len(results))
+	for i, r := range results {
+		items[i] = foodDetail(r.Food)
+		items[i]["score"] = r.Score
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"query":   query,
+		"mode":    foodStore.Mode(),
+		"results": items,
+	})
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"server2/catalog"
+	"server2/models"
+	"strings"
+)
+
+// import [-format csv] [-o data/foods.json] input
+// converts a csv, yaml or schema.org json-ld catalog into canonical foods.json
+func runImport(args []string) int {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	format := fs.String("format", "", "input format ("+strings.Join(catalog.Formats(), ", ")+"), default from the file extension")
+	out := fs.String("o", "-", "output file, - for stdout")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: server import [flags] <menu.csv|menu.yaml|menu.jsonld>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	foods, err := catalog.ReadFile(fs.Arg(0), *format)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	// refuse to write a catalog the server would not load
+	if err := models.ValidateFoods(foods); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	for _, w := range models.LintFoods(foods) {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", w)
+	}
+
+	data, err := catalog.MarshalJSON(foods)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if *out == "-" {
+		os.Stdout.Write(data)
+		return 0
+	}
+	if err := os.WriteFile(*out, data, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "Imported %d foods into %s\n", len(foods), *out)
+	return 0
+}
@@ -1,58 +1,196 @@
 package main
 
 import (
+	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"server2/config"
+	"server2/embedding"
 	"server2/engine"
 	"server2/handlers"
+	"server2/ollama"
 	"server2/openai"
 	"server2/store"
+	"server2/tei"
+	"syscall"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
 
+// set at build time with -ldflags "-X main.version=..."
+var version = "dev"
+
 func main() {
-	// init openAi client
-	openaiClient, err := openai.NewClient()
-	if err != nil {
-		log.Fatalf("Failed to create OpenAI client: %v", err)
+	cfg := config.Load()
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid config: %v", err)
 	}
 
-	// load food data & Generate embeddings
-	dataPath := "data/foods.json"
-	
-	foodStore, err := store.NewFoodStore(dataPath, openaiClient)
+	// subcommands
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "validate":
+			os.Exit(runValidate(cfg, os.Args[2:]))
+		case "import":
+			os.Exit(runImport(os.Args[2:]))
+		}
+	}
+
+	// init the embedding provider
+	embedder, err := newEmbedder(cfg)
 	if err != nil {
-		log.Fatalf("Failed to load foods: %v", err)
+		log.Fatalf("Failed to create embedder: %v", err)
 	}
+	log.Printf("Using %s embeddings", cfg.EmbeddingProvider)
+
+	// the default catalog plus one catalog per file in CATALOGS_DIR
+	foodStore := store.OpenFoodStore(cfg.DataPath, embedder)
+	foodStore.SetFormat(cfg.DataFormat)
+	catalogs := store.NewCatalogs(foodStore)
+	if cfg.CatalogsDir != "" {
+		ids, err := catalogs.OpenDir(cfg.CatalogsDir, embedder)
+		if err != nil {
+			log.Fatalf("Failed to open catalogs: %v", err)
+		}
+		log.Printf("Serving catalogs %v", ids)
+	}
+
+	// load food data & Generate embeddings in the background,
+	// the listener starts right away and reports progress on /readyz
+	for _, id := range catalogs.IDs() {
+		go func(id string, foodStore *store.FoodStore) {
+			foodStore.SetColdStartClusters(cfg.ColdStartClusters)
+			if err := foodStore.Load(); err != nil {
+				if id == store.DefaultCatalog {
+					log.Fatalf("Failed to load foods: %v", err)
+				}
+				// one broken menu should not take the other restaurants down
+				log.Printf("Failed to load catalog %s: %v", id, err)
+				return
+			}
+			reportDuplicates(foodStore, cfg.DuplicateThreshold)
+			go foodStore.Watch(cfg.WatchInterval, nil)
+		}(id, catalogs.Get(id))
+	}
+
+	// SIGHUP reloads the catalogs, only new or edited foods get re-embedded
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	go func() {
+		for range reload {
+			for _, id := range catalogs.IDs() {
+				foodStore := catalogs.Get(id)
+				if err := foodStore.LoadError(); err != nil {
+					log.Printf("Skipping reload of catalog %s: %v", id, err)
+					continue
+				}
+				if !foodStore.Ready() {
+					log.Printf("Catalog %s still loading, skipping reload", id)
+					continue
+				}
+				if _, err := foodStore.Reload(); err != nil {
+					log.Printf("Reload of catalog %s failed, keeping current foods: %v", id, err)
+				}
+			}
+		}
+	}()
 
 	// init the components
-	sessionStore := store.NewSessionStore(openaiClient.GetEmbeddingDimension())
-	recommender := engine.NewRecommender(foodStore)
-	handler := handlers.NewHandler(foodStore, sessionStore, recommender)
+	sessionStore := store.NewSessionStore(catalogs)
+	recommenderConfig := engine.Config{
+		Strategy: cfg.Strategy,
+		Hybrid:   engine.HybridWeights{Semantic: cfg.SemanticWeight, Lexical: cfg.LexicalWeight},
+		Exploration: engine.ExplorationConfig{
+			Lambda:      cfg.ExplorationLambda,
+			Window:      cfg.ExplorationWindow,
+			Epsilon:     cfg.ExplorationEpsilon,
+			Temperature: cfg.ExplorationTemp,
+		},
+		Intent: engine.IntentConfig{
+			Alpha: cfg.IntentAlpha,
+			Beta:  cfg.IntentBeta,
+			Gamma: cfg.IntentGamma,
+			Left:  cfg.LeftSwipeWeight,
+			Right: cfg.RightSwipeWeight,
+			Super: cfg.SuperSwipeWeight,
+		},
+		Recency:         engine.RecencyConfig{Decay: cfg.RecencyDecay, Window: cfg.RecencyWindow},
+		StrategyRecency: make(map[string]engine.RecencyConfig),
+	}
+	// a strategy with either setting of its own gets both, the other from the deployment default
+	for name, decay := range cfg.StrategyRecencyDecay {
+		recency := recommenderConfig.Recency
+		recency.Decay = decay
+		recommenderConfig.StrategyRecency[name] = recency
+	}
+	for name, window := range cfg.StrategyRecencyWindow {
+		recency, ok := recommenderConfig.StrategyRecency[name]
+		if !ok {
+			recency = recommenderConfig.Recency
+		}
+		recency.Window = window
+		recommenderConfig.StrategyRecency[name] = recency
+	}
+	if err := recommenderConfig.Validate(); err != nil {
+		log.Fatalf("Invalid recommender settings: %v", err)
+	}
+	recommender := engine.NewRecommenderWithConfig(catalogs, recommenderConfig)
+	handler := handlers.NewHandler(catalogs, sessionStore, recommender, version)
 
 	r := gin.Default()
 
 	// cors
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "If-None-Match"},
+		ExposeHeaders:    []string{"ETag"},
 		AllowCredentials: true,
 	}))
 
-	r.POST("/session", handler.CreateSession)
-	r.GET("/recommendation", handler.GetRecommendation)
-	r.POST("/swipe", handler.Swipe)
+	r.GET("/health", handler.Healthz)
+	r.GET("/healthz", handler.Healthz)
+	r.GET("/readyz", handler.Readyz)
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8000"
+	// recommendation routes answer 503 until the catalog is ready
+	api := r.Group("/", handler.RequireReady)
+	api.POST("/session", handler.CreateSession)
+	api.GET("/recommendation", handler.GetRecommendation)
+	api.POST("/swipe", handler.Swipe)
+	api.GET("/food-info", handler.FoodInfo)
+	api.GET("/foods", handler.ListFoods)
+	api.GET("/foods/:id", handler.GetFood)
+	api.GET("/search", handler.Search)
+
+	// catalog editing, only mounted when ADMIN_TOKEN is set
+	if cfg.AdminToken != "" {
+		admin := handlers.NewAdminHandler(catalogs, store.NewAuditLog(cfg.AuditLogPath), cfg.AdminToken)
+		adminAPI := r.Group("/admin", handler.RequireReady, admin.RequireToken)
+		adminAPI.POST("/foods", admin.CreateFood)
+		adminAPI.PUT("/foods/:id", admin.UpdateFood)
+		adminAPI.DELETE("/foods/:id", admin.DeleteFood)
 	}
 
-	log.Printf("Server starting on port %s", port)
-	if err := r.Run(":" + port); err != nil {
+	log.Printf("Server starting on port %s", cfg.Port)
+	if err := r.Run(":" + cfg.Port); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
+
+// picks the embedding provider from config
+func newEmbedder(cfg *config.Config) (embedding.Embedder, error) {
+	switch cfg.EmbeddingProvider {
+	case config.ProviderOpenAI:
+		return openai.NewClient()
+	case config.ProviderOllama:
+		return ollama.NewClient(), nil
+	case config.ProviderTEI:
+		return tei.NewClient(), nil
+	case config.ProviderOffline:
+		return embedding.NewHashEmbedder(cfg.OfflineDimension), nil
+	default:
+		return nil, fmt.Errorf("unknown embedding provider %q", cfg.EmbeddingProvider)
+	}
+}
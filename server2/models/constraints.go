@@ -0,0 +1,70 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// hard dietary limits set when a session is created.
+// foods that break any of them are never recommended.
+type Constraints struct {
+	Vegetarian       bool     `json:"vegetarian,omitempty"`
+	Vegan            bool     `json:"vegan,omitempty"`
+	GlutenFree       bool     `json:"gluten_free,omitempty"`
+	Halal            bool     `json:"halal,omitempty"`
+	NoNuts           bool     `json:"no_nuts,omitempty"` // shorthand for excluding nuts and peanuts
+	ExcludeAllergens []string `json:"exclude_allergens,omitempty"`
+	MaxSpiceLevel    *int     `json:"max_spice_level,omitempty"`
+	MaxPriceRange    *int     `json:"max_price_range,omitempty"`
+}
+
+// checks the constraint values themselves
+func (c *Constraints) Validate() error {
+	for _, a := range c.ExcludeAllergens {
+		if !contains(Allergens, a) {
+			return fmt.Errorf("unknown allergen %q (known: %s)", a, strings.Join(Allergens, ", "))
+		}
+	}
+	if c.MaxSpiceLevel != nil && (*c.MaxSpiceLevel < 0 || *c.MaxSpiceLevel > MaxSpiceLevel) {
+		return fmt.Errorf("max_spice_level must be between 0 and %d", MaxSpiceLevel)
+	}
+	if c.MaxPriceRange != nil && (*c.MaxPriceRange < 1 || *c.MaxPriceRange > MaxPriceRange) {
+		return fmt.Errorf("max_price_range must be between 1 and %d", MaxPriceRange)
+	}
+	return nil
+}
+
+// true if the food satisfies every constraint.
+// a food with unknown price never passes a price cap.
+func (c *Constraints) Allows(food *Food) bool {
+	if c == nil {
+		return true
+	}
+	if c.Vegetarian && !food.Vegetarian {
+		return false
+	}
+	if c.Vegan && !food.Vegan {
+		return false
+	}
+	if c.GlutenFree && !food.GlutenFree {
+		return false
+	}
+	if c.Halal && !food.Halal {
+		return false
+	}
+	if c.NoNuts && (food.HasAllergen("nuts") || food.HasAllergen("peanuts")) {
+		return false
+	}
+	for _, a := range c.ExcludeAllergens {
+		if food.HasAllergen(a) {
+			return false
+		}
+	}
+	if c.MaxSpiceLevel != nil && food.SpiceLevel > *c.MaxSpiceLevel {
+		return false
+	}
+	if c.MaxPriceRange != nil && (food.PriceRange == 0 || food.PriceRange > *c.MaxPriceRange) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,86 @@
+package models
+
+import "testing"
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestConstraintsAllows(t *testing.T) {
+	curry := &Food{ID: "1", Name: "Kung Pao Chicken", Allergens: []string{"peanuts", "soy"}, SpiceLevel: 4, PriceRange: 2}
+	dosa := &Food{ID: "2", Name: "Dosa", Vegetarian: true, Vegan: true, GlutenFree: true, Halal: true, SpiceLevel: 0, PriceRange: 1}
+	unpriced := &Food{ID: "3", Name: "Mystery", Vegetarian: true}
+
+	tests := []struct {
+		name string
+		c    Constraints
+		food *Food
+		want bool
+	}{
+		{"no constraints", Constraints{}, curry, true},
+		{"vegetarian blocks meat", Constraints{Vegetarian: true}, curry, false},
+		{"vegan allows vegan", Constraints{Vegan: true}, dosa, true},
+		{"halal blocks non halal", Constraints{Halal: true}, curry, false},
+		{"gluten free allows dosa", Constraints{GlutenFree: true}, dosa, true},
+		{"no nuts blocks peanuts", Constraints{NoNuts: true}, curry, false},
+		{"excluded allergen", Constraints{ExcludeAllergens: []string{"soy"}}, curry, false},
+		{"spice cap", Constraints{MaxSpiceLevel: intPtr(2)}, curry, false},
+		{"spice cap zero", Constraints{MaxSpiceLevel: intPtr(0)}, dosa, true},
+		{"price cap", Constraints{MaxPriceRange: intPtr(1)}, curry, false},
+		{"price cap unknown price", Constraints{MaxPriceRange: intPtr(4)}, unpriced, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.c.Allows(tt.food); got != tt.want {
+				t.Errorf("Allows() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConstraintsValidate(t *testing.T) {
+	bad := []Constraints{
+		{ExcludeAllergens: []string{"celery"}},
+		{MaxSpiceLevel: intPtr(6)},
+		{MaxPriceRange: intPtr(0)},
+	}
+	for _, c := range bad {
+		if err := c.Validate(); err == nil {
+			t.Errorf("Expected %+v to be invalid", c)
+		}
+	}
+
+	ok := Constraints{Vegan: true, ExcludeAllergens: []string{"soy"}, MaxSpiceLevel: intPtr(0), MaxPriceRange: intPtr(2)}
+	if err := ok.Validate(); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestFilterMatches(t *testing.T) {
+	food := &Food{Name: "Pad Thai", Cuisine: "Thai", Course: "main", Tags: []string{"Noodles"}, Allergens: []string{"peanuts"}}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   bool
+	}{
+		{"empty", Filter{}, true},
+		{"cuisine ignores case", Filter{Cuisine: "thai"}, true},
+		{"other cuisine", Filter{Cuisine: "Indian"}, false},
+		{"course", Filter{Course: "dessert"}, false},
+		{"tag ignores case", Filter{Tag: "noodles"}, true},
+		{"missing tag", Filter{Tag: "soup"}, false},
+		{"constraints apply", Filter{Cuisine: "Thai", Constraints: Constraints{NoNuts: true}}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.filter.Matches(food); got != tt.want {
+			t.Errorf("%s: Matches() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	bad := Filter{Course: "brunch"}
+	if bad.Validate() == nil {
+		t.Error("Unknown course should fail validation")
+	}
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// attribute filters for browsing and searching the catalog.
+// the zero value matches every food.
+type Filter struct {
+	Constraints
+	Cuisine string `json:"cuisine,omitempty"` // case-insensitive
+	Course  string `json:"course,omitempty"`
+	Tag     string `json:"tag,omitempty"` // case-insensitive
+}
+
+// checks the filter values themselves
+func (f *Filter) Validate() error {
+	if f.Course != "" && !contains(Courses, f.Course) {
+		return fmt.Errorf("course must be one of %s", strings.Join(Courses, ", "))
+	}
+	return f.Constraints.Validate()
+}
+
+// true if the food passes every filter
+func (f *Filter) Matches(food *Food) bool {
+	if f == nil {
+		return true
+	}
+	if !f.Constraints.Allows(food) {
+		return false
+	}
+	if f.Cuisine != "" && !strings.EqualFold(f.Cuisine, food.Cuisine) {
+		return false
+	}
+	if f.Course != "" && f.Course != food.Course {
+		return false
+	}
+	if f.Tag != "" {
+		found := false
+		for _, tag := range food.Tags {
+			if strings.EqualFold(tag, f.Tag) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
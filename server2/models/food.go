@@ -1,10 +1,64 @@
 package models
 
+import "fmt"
+
 // food item with its metadata
 type Food struct {
-	ID          string `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Aliases     []string   `json:"aliases,omitempty"` // other names the dish is known by
+	Description string     `json:"description"`
+	Cuisine     string     `json:"cuisine,omitempty"`
+	Course      string     `json:"course,omitempty"` // one of Courses
+	Tags        []string   `json:"tags,omitempty"`
+	Vegetarian  bool       `json:"vegetarian"`
+	Vegan       bool       `json:"vegan"`
+	GlutenFree  bool       `json:"gluten_free"`
+	Halal       bool       `json:"halal"`
+	Allergens   []string   `json:"allergens,omitempty"`   // from Allergens
+	SpiceLevel  int        `json:"spice_level"`           // 0 (none) to MaxSpiceLevel
+	PriceRange  int        `json:"price_range,omitempty"` // 1 ($) to MaxPriceRange ($$$$), 0 = unknown
+	PrepTime    int        `json:"prep_time_minutes,omitempty"`
+	ImageURL    string     `json:"image_url,omitempty"`
+	Nutrients   *Nutrients `json:"nutrients,omitempty"`
+}
+
+// allowed values for the structured food fields
+const (
+	MaxSpiceLevel = 5
+	MaxPriceRange = 4
+)
+
+var Courses = []string{"starter", "main", "side", "dessert", "drink", "snack", "breakfast"}
+
+var Allergens = []string{"gluten", "dairy", "egg", "nuts", "peanuts", "soy", "fish", "shellfish", "sesame"}
+
+// true if the food lists the allergen
+func (f *Food) HasAllergen(allergen string) bool {
+	for _, a := range f.Allergens {
+		if a == allergen {
+			return true
+		}
+	}
+	return false
+}
+
+// nutrition facts per serving
+type Nutrients struct {
+	Calories int     `json:"calories"`
+	ProteinG float64 `json:"protein_g"`
+	CarbsG   float64 `json:"carbs_g"`
+	FatG     float64 `json:"fat_g"`
+	FiberG   float64 `json:"fiber_g"`
+}
+
+// one line summary, e.g. "490 kcal · 32g protein · 14g carbs · 34g fat · 3g fiber"
+func (n *Nutrients) Summary() string {
+	if n == nil {
+		return "Nutrition info not available"
+	}
+	return fmt.Sprintf("%d kcal · %gg protein · %gg carbs · %gg fat · %gg fiber (per serving)",
+		n.Calories, n.ProteinG, n.CarbsG, n.FatG, n.FiberG)
 }
 
 // food item with its embedding vector
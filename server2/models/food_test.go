@@ -0,0 +1,130 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func validFood() Food {
+	return Food{
+		ID:          "1",
+		Name:        "Palak Paneer",
+		Description: "Spinach curry with cubes of fresh paneer",
+		Cuisine:     "Indian",
+		Course:      "main",
+		Tags:        []string{"curry"},
+		Vegetarian:  true,
+		GlutenFree:  true,
+		Allergens:   []string{"dairy"},
+		SpiceLevel:  1,
+		PriceRange:  2,
+		PrepTime:    35,
+		ImageURL:    "https://example.com/palak.jpg",
+	}
+}
+
+func TestValidFoodPasses(t *testing.T) {
+	f := validFood()
+	if errs := f.Validate(); len(errs) != 0 {
+		t.Errorf("Expected no errors, got %v", errs)
+	}
+}
+
+func TestFoodValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(*Food)
+		field  string
+	}{
+		{"missing id", func(f *Food) { f.ID = "" }, "id"},
+		{"missing name", func(f *Food) { f.Name = " " }, "name"},
+		{"long name", func(f *Food) { f.Name = strings.Repeat("a", MaxNameLength+1) }, "name"},
+		{"missing description", func(f *Food) { f.Description = "" }, "description"},
+		{"long description", func(f *Food) { f.Description = strings.Repeat("a", MaxDescriptionLength+1) }, "description"},
+		{"bad course", func(f *Food) { f.Course = "brunch" }, "course"},
+		{"unknown allergen", func(f *Food) { f.Allergens = []string{"celery"} }, "allergens"},
+		{"vegan not vegetarian", func(f *Food) { f.Vegan, f.Vegetarian = true, false }, "vegan"},
+		{"gluten free with gluten", func(f *Food) { f.Allergens = []string{"gluten"} }, "gluten_free"},
+		{"spice too high", func(f *Food) { f.SpiceLevel = 9 }, "spice_level"},
+		{"price too high", func(f *Food) { f.PriceRange = 5 }, "price_range"},
+		{"negative prep time", func(f *Food) { f.PrepTime = -1 }, "prep_time_minutes"},
+		{"relative image url", func(f *Food) { f.ImageURL = "img/palak.jpg" }, "image_url"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := validFood()
+			tt.mutate(&f)
+
+			errs := f.Validate()
+			if len(errs) != 1 || errs[0].Field != tt.field {
+				t.Errorf("Expected one %s error, got %v", tt.field, errs)
+			}
+		})
+	}
+}
+
+func TestValidateFoodsReportsEveryProblem(t *testing.T) {
+	bad := validFood()
+	bad.ID = "2"
+	bad.Name = "Saag Paneer"
+	bad.SpiceLevel = -1
+	bad.Course = "brunch"
+
+	err := ValidateFoods([]Food{validFood(), bad})
+
+	var verrs ValidationErrors
+	if !errors.As(err, &verrs) || len(verrs) != 2 {
+		t.Fatalf("Expected 2 validation errors, got %v", err)
+	}
+	if !strings.Contains(err.Error(), `food "2" (Saag Paneer): spice_level`) {
+		t.Errorf("Error should name the food and field, got:\n%v", err)
+	}
+}
+
+func TestNutrientsSummary(t *testing.T) {
+	var missing *Nutrients
+	if missing.Summary() == "" {
+		t.Error("Nil nutrients should still give a summary")
+	}
+
+	n := &Nutrients{Calories: 350, ProteinG: 20, CarbsG: 10, FatG: 26, FiberG: 2}
+	if !strings.HasPrefix(n.Summary(), "350 kcal · 20g protein") {
+		t.Errorf("Unexpected summary %q", n.Summary())
+	}
+}
+
+func TestValidateFoodsRejectsDuplicates(t *testing.T) {
+	dupID := validFood()
+	dupID.Name = "Saag Paneer"
+	dupName := validFood()
+	dupName.ID = "3"
+	dupName.Name = "  palak   PANEER "
+
+	var verrs ValidationErrors
+	if err := ValidateFoods([]Food{validFood(), dupID, dupName}); !errors.As(err, &verrs) || len(verrs) != 2 {
+		t.Fatalf("Expected a duplicate id and a duplicate name error, got %v", err)
+	}
+	if verrs[0].Field != "id" || verrs[1].Field != "name" || !strings.Contains(verrs[1].Reason, `"1"`) {
+		t.Errorf("Unexpected errors: %v", verrs)
+	}
+}
+
+func TestLintFoods(t *testing.T) {
+	a := validFood()
+	a.Aliases = []string{"Saag Paneer"}
+	b := validFood()
+	b.ID, b.Name, b.Description = "2", "Saag Paneer", "Greens"
+
+	warnings := LintFoods([]Food{a, b})
+	if len(warnings) != 2 {
+		t.Fatalf("Expected an alias clash and a short description, got %v", warnings)
+	}
+	if warnings[0].Field != "aliases" || warnings[1].Field != "description" {
+		t.Errorf("Unexpected warnings: %v", warnings)
+	}
+	if err := ValidateFoods([]Food{a, b}); err != nil {
+		t.Errorf("Warnings should not fail validation, got %v", err)
+	}
+}
@@ -4,17 +4,28 @@ import (
 	"sync"
 )
 
-//  represents a user's food selection session
+// represents a user's food selection session
 type Session struct {
 	ID           string
 	IntentVector []float64
+	IntentSpace  string    // catalog mode the intent vector was built in
+	QueryVector  []float64 // embedded Query, nil without one
+	Positive     Centroid  // foods swiped right or super
+	Negative     Centroid  // foods swiped left
+	Swipes       []Swipe
+	Catalog      string      // catalog ID, fixed at creation
+	Constraints  Constraints // fixed at creation, no lock needed to read
+	Query        string      // free text the intent was seeded with, fixed at creation
+	Seed         int64       // seeds random exploration, fixed at creation
+	Strategy     string      // recommendation strategy, "" for the deployment default
 	SeenFoods    map[string]bool
+	Shown        []string // food IDs in the order they were shown
 	Completed    bool
 	FinalChoice  string
 	mu           sync.RWMutex
 }
 
-//  creates a new session with a neutral intent vector
+// creates a new session with a neutral intent vector
 func NewSession(id string, vectorSize int) *Session {
 	intent := make([]float64, vectorSize)
 	return &Session{
@@ -25,27 +36,138 @@ func NewSession(id string, vectorSize int) *Session {
 	}
 }
 
-//  marks a food as seen in this session
+// marks a food as seen in this session
 func (s *Session) MarkSeen(foodID string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	if !s.SeenFoods[foodID] {
+		s.Shown = append(s.Shown, foodID)
+	}
 	s.SeenFoods[foodID] = true
 }
 
-//  checks if a food has been seen
+// returns how many foods the session has been shown
+func (s *Session) SeenCount() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.Shown)
+}
+
+// returns the IDs of the last n foods shown, oldest first
+func (s *Session) RecentlyShown(n int) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if n > len(s.Shown) {
+		n = len(s.Shown)
+	}
+	if n <= 0 {
+		return nil
+	}
+	result := make([]string, n)
+	copy(result, s.Shown[len(s.Shown)-n:])
+	return result
+}
+
+// checks if a food has been seen
 func (s *Session) HasSeen(foodID string) bool {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	return s.SeenFoods[foodID]
 }
 
-//  updates the session's intent vector
+// updates the session's intent vector
 func (s *Session) UpdateIntent(newIntent []float64) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.IntentVector = newIntent
 }
 
+// replaces the intent vector along with the space it belongs to
+func (s *Session) SetIntent(space string, intent []float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.IntentSpace = space
+	s.IntentVector = intent
+}
+
+// sets the embedded query the intent starts from
+func (s *Session) SetQueryVector(v []float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.QueryVector = v
+}
+
+// returns a copy of the embedded query, nil without one
+func (s *Session) GetQueryVector() []float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.QueryVector == nil {
+		return nil
+	}
+	result := make([]float64, len(s.QueryVector))
+	copy(result, s.QueryVector)
+	return result
+}
+
+// adds a swiped food's embedding to the positive centroid (weight > 0)
+// or the negative one (weight < 0), with the weight's magnitude.
+// embeddings from another vector space are skipped, see Centroid.Add.
+func (s *Session) AddFeedback(embedding []float64, weight float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if weight > 0 {
+		s.Positive.Add(embedding, weight)
+	} else if weight < 0 {
+		s.Negative.Add(embedding, -weight)
+	}
+}
+
+// fades both centroids by a factor, before adding a newer swipe
+func (s *Session) DecayFeedback(factor float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Positive.Decay(factor)
+	s.Negative.Decay(factor)
+}
+
+// returns the positive and negative centroids, nil while empty
+func (s *Session) Feedback() (positive, negative []float64) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Positive.Mean(), s.Negative.Mean()
+}
+
+// empties both centroids, before replaying swipes into a new vector space
+func (s *Session) ResetFeedback() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Positive = Centroid{}
+	s.Negative = Centroid{}
+}
+
+// returns the space the intent vector belongs to
+func (s *Session) GetIntentSpace() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.IntentSpace
+}
+
+// appends a swipe to the session history
+func (s *Session) RecordSwipe(foodID, action string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Swipes = append(s.Swipes, Swipe{FoodID: foodID, Action: action})
+}
+
+// returns a copy of the swipe history
+func (s *Session) GetSwipes() []Swipe {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]Swipe, len(s.Swipes))
+	copy(result, s.Swipes)
+	return result
+}
+
 // returns a copy of the intent vector
 func (s *Session) GetIntent() []float64 {
 	s.mu.RLock()
@@ -55,7 +177,7 @@ func (s *Session) GetIntent() []float64 {
 	return result
 }
 
-//  marks the session as completed with the final choice
+// marks the session as completed with the final choice
 func (s *Session) Complete(foodName string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -63,10 +185,9 @@ func (s *Session) Complete(foodName string) {
 	s.FinalChoice = foodName
 }
 
-//  checks if the session is completed
+// checks if the session is completed
 func (s *Session) IsCompleted() bool {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	return s.Completed
 }
-
@@ -85,3 +85,24 @@ func TestSessionComplete(t *testing.T) {
 		t.Errorf("Final choice should be 'Pizza', got '%s'", session.FinalChoice)
 	}
 }
+
+func TestCentroidSkipsMismatchedVectors(t *testing.T) {
+	var c Centroid
+	if !c.Add([]float64{1, 0}, 1) || !c.Add([]float64{0, 1}, 1) {
+		t.Fatal("Vectors of the same length should be added")
+	}
+	if c.Add([]float64{1, 1, 1}, 1) {
+		t.Error("A vector of another length should be skipped")
+	}
+	if mean := c.Mean(); len(mean) != 2 || mean[0] != 0.5 || mean[1] != 0.5 {
+		t.Errorf("Skipped vector should not reset the centroid, mean = %v", mean)
+	}
+
+	session := NewSession("test", 2)
+	session.AddFeedback([]float64{1, 0}, 1)
+	session.ResetFeedback()
+	session.AddFeedback([]float64{0, 0, 1}, 1)
+	if positive, _ := session.Feedback(); len(positive) != 3 {
+		t.Errorf("ResetFeedback should make room for the new space, got %v", positive)
+	}
+}
@@ -0,0 +1,52 @@
+package models
+
+// one swipe made in a session
+type Swipe struct {
+	FoodID string `json:"food_id"`
+	Action string `json:"action"` // left, right or super
+}
+
+// running sum of swiped food embeddings, each scaled by its swipe weight.
+// the mean divides by the number of swipes, so heavier swipes pull harder.
+type Centroid struct {
+	Sum   []float64
+	Count int
+}
+
+// adds an embedding with a weight. a vector whose length differs from the
+// ones added before is skipped and false returned, the centroid is only
+// emptied by Session.ResetFeedback when the vector space changes.
+func (c *Centroid) Add(v []float64, weight float64) bool {
+	if len(v) == 0 || (len(c.Sum) > 0 && len(c.Sum) != len(v)) {
+		return false
+	}
+	if len(c.Sum) == 0 {
+		c.Sum = make([]float64, len(v))
+	}
+	for i := range v {
+		c.Sum[i] += v[i] * weight
+	}
+	c.Count++
+	return true
+}
+
+// scales down every embedding added so far, the swipe count stays.
+// the centroid's magnitude shrinks, so older swipes pull less on the intent
+// and a newer swipe in the other centroid can outweigh them.
+func (c *Centroid) Decay(factor float64) {
+	for i := range c.Sum {
+		c.Sum[i] *= factor
+	}
+}
+
+// weighted sum over the number of swipes, nil while empty
+func (c Centroid) Mean() []float64 {
+	if c.Count == 0 {
+		return nil
+	}
+	mean := make([]float64, len(c.Sum))
+	for i := range c.Sum {
+		mean[i] = c.Sum[i] / float64(c.Count)
+	}
+	return mean
+}
@@ -0,0 +1,178 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// a problem with one field of one food
+type FieldError struct {
+	FoodID string
+	Name   string
+	Field  string
+	Reason string
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("food %q (%s): %s %s", e.FoodID, e.Name, e.Field, e.Reason)
+}
+
+// all problems found in a catalog
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	lines := make([]string, len(e))
+	for i, fe := range e {
+		lines[i] = "  - " + fe.Error()
+	}
+	return fmt.Sprintf("%d invalid field(s) in catalog:\n%s", len(e), strings.Join(lines, "\n"))
+}
+
+// limits on free text fields
+const (
+	MaxNameLength        = 80
+	MaxDescriptionLength = 1000
+	MinDescriptionLength = 20 // shorter descriptions only get a warning
+)
+
+// checks the structured fields of a food
+func (f *Food) Validate() ValidationErrors {
+	var errs ValidationErrors
+	add := func(field, reason string, args ...interface{}) {
+		errs = append(errs, FieldError{FoodID: f.ID, Name: f.Name, Field: field, Reason: fmt.Sprintf(reason, args...)})
+	}
+
+	if strings.TrimSpace(f.ID) == "" {
+		add("id", "is required")
+	}
+	if strings.TrimSpace(f.Name) == "" {
+		add("name", "is required")
+	} else if len(f.Name) > MaxNameLength {
+		add("name", "is longer than %d characters", MaxNameLength)
+	}
+	// the description is half of the embedded text, without it vectors are mostly noise
+	if strings.TrimSpace(f.Description) == "" {
+		add("description", "is required")
+	} else if len(f.Description) > MaxDescriptionLength {
+		add("description", "is longer than %d characters (%d)", MaxDescriptionLength, len(f.Description))
+	}
+	if f.Course != "" && !contains(Courses, f.Course) {
+		add("course", "must be one of %s, got %q", strings.Join(Courses, ", "), f.Course)
+	}
+	for _, a := range f.Allergens {
+		if !contains(Allergens, a) {
+			add("allergens", "has unknown allergen %q (known: %s)", a, strings.Join(Allergens, ", "))
+		}
+	}
+	for _, alias := range f.Aliases {
+		if strings.TrimSpace(alias) == "" {
+			add("aliases", "must not contain empty aliases")
+			break
+		}
+	}
+	for _, tag := range f.Tags {
+		if strings.TrimSpace(tag) == "" {
+			add("tags", "must not contain empty tags")
+			break
+		}
+	}
+	if f.Vegan && !f.Vegetarian {
+		add("vegan", "requires vegetarian to be true")
+	}
+	if f.GlutenFree && f.HasAllergen("gluten") {
+		add("gluten_free", "conflicts with the gluten allergen")
+	}
+	if f.SpiceLevel < 0 || f.SpiceLevel > MaxSpiceLevel {
+		add("spice_level", "must be between 0 and %d, got %d", MaxSpiceLevel, f.SpiceLevel)
+	}
+	if f.PriceRange < 0 || f.PriceRange > MaxPriceRange {
+		add("price_range", "must be between 1 and %d (or omitted), got %d", MaxPriceRange, f.PriceRange)
+	}
+	if f.PrepTime < 0 {
+		add("prep_time_minutes", "must not be negative, got %d", f.PrepTime)
+	}
+	if f.ImageURL != "" {
+		if u, err := url.Parse(f.ImageURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add("image_url", "must be an absolute http(s) URL, got %q", f.ImageURL)
+		}
+	}
+	if n := f.Nutrients; n != nil && (n.Calories < 0 || n.ProteinG < 0 || n.CarbsG < 0 || n.FatG < 0 || n.FiberG < 0) {
+		add("nutrients", "must not be negative")
+	}
+
+	return errs
+}
+
+// validates every food, returns nil when the catalog is fine
+func ValidateFoods(foods []Food) error {
+	var errs ValidationErrors
+	for i := range foods {
+		errs = append(errs, foods[i].Validate()...)
+	}
+
+	// duplicate IDs would overwrite each other in the ID index,
+	// duplicate names make name lookups ambiguous
+	ids := make(map[string]bool, len(foods))
+	names := make(map[string]string, len(foods))
+	for _, f := range foods {
+		if f.ID != "" && ids[f.ID] {
+			errs = append(errs, FieldError{FoodID: f.ID, Name: f.Name, Field: "id", Reason: "is used by more than one food"})
+		}
+		ids[f.ID] = true
+
+		key := NormalizeName(f.Name)
+		if first, ok := names[key]; ok && key != "" {
+			errs = append(errs, FieldError{FoodID: f.ID, Name: f.Name, Field: "name", Reason: fmt.Sprintf("duplicates food %q", first)})
+		} else {
+			names[key] = f.ID
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// soft problems that do not stop the catalog from loading:
+// aliases that collide with another food's name or alias, and very short descriptions
+func LintFoods(foods []Food) ValidationErrors {
+	var warnings ValidationErrors
+
+	owner := make(map[string]string, len(foods))
+	for _, f := range foods {
+		owner[NormalizeName(f.Name)] = f.ID
+	}
+	for _, f := range foods {
+		for _, alias := range f.Aliases {
+			key := NormalizeName(alias)
+			if id, ok := owner[key]; ok && id != f.ID {
+				warnings = append(warnings, FieldError{FoodID: f.ID, Name: f.Name, Field: "aliases",
+					Reason: fmt.Sprintf("%q is already used by food %q", alias, id)})
+				continue
+			}
+			owner[key] = f.ID
+		}
+
+		if n := len(strings.TrimSpace(f.Description)); n > 0 && n < MinDescriptionLength {
+			warnings = append(warnings, FieldError{FoodID: f.ID, Name: f.Name, Field: "description",
+				Reason: fmt.Sprintf("is very short (%d characters)", n)})
+		}
+	}
+	return warnings
+}
+
+// lowercases a name and collapses whitespace, so names compare the way people read them
+func NormalizeName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+func contains(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
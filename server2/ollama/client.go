@@ -0,0 +1,142 @@
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"server2/embedding"
+	"strings"
+	"time"
+)
+
+const DefaultBaseURL = "http://localhost:11434"
+const DefaultModel = "nomic-embed-text"
+const DefaultTimeout = 60 * time.Second
+
+// client settings, zero values fall back to the defaults above
+type Config struct {
+	BaseURL string
+	Model   string
+	Timeout time.Duration
+}
+
+// embedding client for Ollama's /api/embeddings endpoint
+type Client struct {
+	config     Config
+	httpClient *http.Client
+
+	dimension embedding.LearnedDimension
+}
+
+// creates a new Ollama client from env vars
+func NewClient() *Client {
+	return NewClientWithConfig(ConfigFromEnv())
+}
+
+// reads client settings from OLLAMA_* env vars
+func ConfigFromEnv() Config {
+	cfg := Config{
+		BaseURL: os.Getenv("OLLAMA_BASE_URL"),
+		Model:   os.Getenv("OLLAMA_EMBEDDING_MODEL"),
+	}
+	if d, err := time.ParseDuration(os.Getenv("OLLAMA_TIMEOUT")); err == nil {
+		cfg.Timeout = d
+	}
+	return cfg
+}
+
+// creates a new Ollama client with explicit settings
+func NewClientWithConfig(cfg Config) *Client {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DefaultBaseURL
+	}
+	if cfg.Model == "" {
+		cfg.Model = DefaultModel
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+
+	return &Client{
+		config:     cfg,
+		httpClient: &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// request body for /api/embeddings
+type EmbeddingRequest struct {
+	Model  string `json:"model"`
+	Prompt string `json:"prompt"`
+}
+
+// response from /api/embeddings
+type EmbeddingResponse struct {
+	Embedding []float64 `json:"embedding"`
+}
+
+// generates an embedding for the given text
+func (c *Client) GetEmbedding(text string) ([]float64, error) {
+	return c.GetEmbeddingContext(context.Background(), text)
+}
+
+// generates an embedding, giving up when ctx is done
+func (c *Client) GetEmbeddingContext(ctx context.Context, text string) ([]float64, error) {
+	jsonBody, err := json.Marshal(EmbeddingRequest{Model: c.config.Model, Prompt: text})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.config.BaseURL+"/api/embeddings", bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ollama error %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var embResp EmbeddingResponse
+	if err := json.Unmarshal(body, &embResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if len(embResp.Embedding) == 0 {
+		return nil, fmt.Errorf("no embedding returned")
+	}
+
+	if err := c.dimension.Learn(len(embResp.Embedding)); err != nil {
+		return nil, err
+	}
+	return embResp.Embedding, nil
+}
+
+// returns the embedding model name
+func (c *Client) Model() string {
+	return "ollama/" + c.config.Model
+}
+
+// returns the embedding dimension learned so far, 0 before the first response
+func (c *Client) Dimension() int {
+	return c.dimension.Get()
+}
+
+// returns the embedding dimension, probing the server once if unknown
+func (c *Client) ProbeDimension() (int, error) {
+	return c.dimension.Probe(c)
+}
@@ -0,0 +1,85 @@
+package ollama
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fake ollama server that embeds by text length
+func newFakeServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/embeddings" {
+			http.NotFound(w, r)
+			return
+		}
+
+		var req EmbeddingRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "bad json", http.StatusBadRequest)
+			return
+		}
+		if req.Model != "test-model" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"model \"` + req.Model + `\" not found, try pulling it first"}`))
+			return
+		}
+
+		json.NewEncoder(w).Encode(EmbeddingResponse{
+			Embedding: []float64{float64(len(req.Prompt)), 1, 0, 0},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGetEmbedding(t *testing.T) {
+	server := newFakeServer(t)
+	c := NewClientWithConfig(Config{BaseURL: server.URL + "/", Model: "test-model"})
+
+	vec, err := c.GetEmbedding("pizza")
+	if err != nil {
+		t.Fatalf("GetEmbedding() error = %v", err)
+	}
+	if len(vec) != 4 || vec[0] != 5 {
+		t.Errorf("Unexpected embedding %v", vec)
+	}
+	if c.Dimension() != 4 {
+		t.Errorf("Dimension() = %d, want 4", c.Dimension())
+	}
+}
+
+func TestDimensionProbe(t *testing.T) {
+	server := newFakeServer(t)
+	c := NewClientWithConfig(Config{BaseURL: server.URL, Model: "test-model"})
+
+	if d := c.Dimension(); d != 0 {
+		t.Errorf("Dimension() = %d before any response, want 0", d)
+	}
+	if d, err := c.ProbeDimension(); d != 4 || err != nil {
+		t.Errorf("ProbeDimension() = %d, %v, want 4", d, err)
+	}
+}
+
+func TestServerError(t *testing.T) {
+	server := newFakeServer(t)
+	c := NewClientWithConfig(Config{BaseURL: server.URL, Model: "missing"})
+
+	_, err := c.GetEmbedding("pizza")
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+}
+
+func TestDefaults(t *testing.T) {
+	c := NewClientWithConfig(Config{})
+	if c.config.BaseURL != DefaultBaseURL || c.config.Model != DefaultModel {
+		t.Errorf("Unexpected defaults %+v", c.config)
+	}
+	if c.Model() != "ollama/"+DefaultModel {
+		t.Errorf("Model() = %q", c.Model())
+	}
+}
@@ -2,58 +2,254 @@ package openai
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"server2/embedding"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
-const embeddingModel = "text-embedding-3-small"
-const embeddingURL = "https://api.openai.com/v1/embeddings"
+const DefaultModel = "text-embedding-3-small"
+const DefaultBaseURL = "https://api.openai.com/v1"
+
+// native output sizes of known embedding models
+var modelDimensions = map[string]int{
+	"text-embedding-3-small": 1536,
+	"text-embedding-3-large": 3072,
+	"text-embedding-ada-002": 1536,
+}
+
+// batch limits for a single embeddings request
+const (
+	maxBatchItems  = 512    // API allows 2048, keep requests small
+	maxBatchTokens = 200000 // API allows 300k tokens per request
+	maxInputTokens = 8191   // per input limit of the model
+)
 
 func init() {
 	_ = godotenv.Load()
 }
 
+// client settings, zero values fall back to the defaults below
+type Config struct {
+	APIKey       string
+	Model        string // model name, or deployment name on Azure
+	BaseURL      string // e.g. https://api.openai.com/v1 or http://localhost:8080/v1
+	Organization string
+	APIVersion   string // set for Azure OpenAI, switches to deployment URLs and api-key auth
+	Dimensions   int    // asks the model for shorter vectors (text-embedding-3 only)
+
+	Timeout     time.Duration // per attempt
+	MaxRetries  int           // negative disables retries, 0 picks DefaultMaxRetries
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+}
+
+// default client settings
+const (
+	DefaultTimeout     = 30 * time.Second
+	DefaultMaxRetries  = 4
+	DefaultBaseBackoff = 500 * time.Millisecond
+	DefaultMaxBackoff  = 20 * time.Second
+)
 
 type Client struct {
-	apiKey     string
+	config     Config
+	url        string
 	httpClient *http.Client
+
+	dimension embedding.LearnedDimension
 }
 
-// creates a new OpenAI client
+// creates a new OpenAI client from env vars
 func NewClient() (*Client, error) {
-	apiKey := os.Getenv("OPENAI_API_KEY")
-	if apiKey == "" {
+	return NewClientWithConfig(ConfigFromEnv())
+}
+
+// reads client settings from OPENAI_* env vars
+func ConfigFromEnv() Config {
+	cfg := Config{
+		APIKey:       os.Getenv("OPENAI_API_KEY"),
+		Model:        os.Getenv("OPENAI_EMBEDDING_MODEL"),
+		BaseURL:      os.Getenv("OPENAI_BASE_URL"),
+		Organization: os.Getenv("OPENAI_ORGANIZATION"),
+		APIVersion:   os.Getenv("OPENAI_API_VERSION"),
+	}
+
+	if n, err := strconv.Atoi(os.Getenv("OPENAI_EMBEDDING_DIMENSIONS")); err == nil {
+		cfg.Dimensions = n
+	}
+	if d, err := time.ParseDuration(os.Getenv("OPENAI_TIMEOUT")); err == nil {
+		cfg.Timeout = d
+	}
+	// an explicit 0 means no retries, a zero Config means the default
+	if n, err := strconv.Atoi(os.Getenv("OPENAI_MAX_RETRIES")); err == nil {
+		cfg.MaxRetries = n
+		if n == 0 {
+			cfg.MaxRetries = -1
+		}
+	}
+	return cfg
+}
+
+// creates a new OpenAI client with explicit settings
+func NewClientWithConfig(cfg Config) (*Client, error) {
+	// local compatible servers usually don't need a key, the real API does
+	if cfg.APIKey == "" && cfg.BaseURL == "" {
 		return nil, fmt.Errorf("OPENAI_API_KEY environment variable not set")
 	}
+	if cfg.Model == "" {
+		cfg.Model = DefaultModel
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DefaultBaseURL
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	} else if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = DefaultMaxRetries
+	}
+	if cfg.BaseBackoff <= 0 {
+		cfg.BaseBackoff = DefaultBaseBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = DefaultMaxBackoff
+	}
+
 	return &Client{
-		apiKey:     apiKey,
-		httpClient: &http.Client{},
+		config:     cfg,
+		url:        embeddingsURL(cfg),
+		httpClient: &http.Client{Timeout: cfg.Timeout},
 	}, nil
 }
 
-//  request body for the embedding API
+// builds the embeddings endpoint, Azure routes by deployment and api-version
+func embeddingsURL(cfg Config) string {
+	if cfg.APIVersion != "" {
+		return fmt.Sprintf("%s/openai/deployments/%s/embeddings?api-version=%s",
+			cfg.BaseURL, url.PathEscape(cfg.Model), url.QueryEscape(cfg.APIVersion))
+	}
+	return cfg.BaseURL + "/embeddings"
+}
+
+// request body for the embedding API
 type EmbeddingRequest struct {
-	Model string `json:"model"`
-	Input string `json:"input"`
+	Model      string   `json:"model,omitempty"`
+	Input      []string `json:"input"`
+	Dimensions int      `json:"dimensions,omitempty"`
 }
 
-//  response from the embedding API
+// response from the embedding API
 type EmbeddingResponse struct {
 	Data []struct {
+		Index     int       `json:"index"`
 		Embedding []float64 `json:"embedding"`
 	} `json:"data"`
 }
 
-//  generates an embedding for the given text
+// generates an embedding for the given text
 func (c *Client) GetEmbedding(text string) ([]float64, error) {
+	return c.GetEmbeddingContext(context.Background(), text)
+}
+
+// generates an embedding, giving up when ctx is done
+func (c *Client) GetEmbeddingContext(ctx context.Context, text string) ([]float64, error) {
+	embeddings, err := c.embedWithRetry(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return embeddings[0], nil
+}
+
+// generates embeddings for many texts, split into as few requests as the limits allow
+func (c *Client) GetEmbeddings(texts []string) ([][]float64, error) {
+	return c.GetEmbeddingsContext(context.Background(), texts)
+}
+
+// batched GetEmbeddings, giving up when ctx is done
+func (c *Client) GetEmbeddingsContext(ctx context.Context, texts []string) ([][]float64, error) {
+	result := make([][]float64, 0, len(texts))
+	for _, batch := range chunkTexts(texts) {
+		embeddings, err := c.embedWithRetry(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, embeddings...)
+	}
+	return result, nil
+}
+
+// splits texts into batches by item count and estimated token budget
+func chunkTexts(texts []string) [][]string {
+	var batches [][]string
+	var batch []string
+	tokens := 0
+
+	for _, text := range texts {
+		n := estimateTokens(text)
+		if len(batch) > 0 && (len(batch) >= maxBatchItems || tokens+n > maxBatchTokens) {
+			batches = append(batches, batch)
+			batch = nil
+			tokens = 0
+		}
+		batch = append(batch, text)
+		tokens += n
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+	return batches
+}
+
+// rough token count (~4 chars per token for english), capped at the model limit
+func estimateTokens(text string) int {
+	n := len(text)/4 + 1
+	if n > maxInputTokens {
+		n = maxInputTokens
+	}
+	return n
+}
+
+// calls embed, retrying transient failures with exponential backoff
+func (c *Client) embedWithRetry(ctx context.Context, texts []string) ([][]float64, error) {
+	for attempt := 0; ; attempt++ {
+		embeddings, err := c.embed(ctx, texts)
+		if err == nil {
+			return embeddings, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if attempt >= c.config.MaxRetries || !isRetryable(err) {
+			return nil, err
+		}
+
+		wait := c.backoff(attempt+1, err)
+		fmt.Printf("Embedding request failed (%v), retrying in %s\n", err, wait.Round(time.Millisecond))
+		if err := sleepContext(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// sends one embeddings request and returns vectors in input order
+func (c *Client) embed(ctx context.Context, texts []string) ([][]float64, error) {
 	reqBody := EmbeddingRequest{
-		Model: embeddingModel,
-		Input: text,
+		Model:      c.config.Model,
+		Input:      texts,
+		Dimensions: c.config.Dimensions,
 	}
 
 	jsonBody, err := json.Marshal(reqBody)
@@ -61,27 +257,35 @@ func (c *Client) GetEmbedding(text string) ([]float64, error) {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", embeddingURL, bytes.NewBuffer(jsonBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", c.url, bytes.NewBuffer(jsonBody))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	switch {
+	case c.config.APIVersion != "":
+		req.Header.Set("api-key", c.config.APIKey)
+	case c.config.APIKey != "":
+		req.Header.Set("Authorization", "Bearer "+c.config.APIKey)
+	}
+	if c.config.Organization != "" {
+		req.Header.Set("OpenAI-Organization", c.config.Organization)
+	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %w", err)
+		return nil, &requestError{fmt.Errorf("failed to make request: %w", err)}
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, &requestError{fmt.Errorf("failed to read response: %w", err)}
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error: %s", string(body))
+		return nil, parseAPIError(resp, body)
 	}
 
 	var embResp EmbeddingResponse
@@ -89,14 +293,59 @@ func (c *Client) GetEmbedding(text string) ([]float64, error) {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
-	if len(embResp.Data) == 0 {
-		return nil, fmt.Errorf("no embedding returned")
+	if len(embResp.Data) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(embResp.Data))
+	}
+
+	embeddings := make([][]float64, len(texts))
+	for _, d := range embResp.Data {
+		if d.Index < 0 || d.Index >= len(texts) {
+			return nil, fmt.Errorf("embedding index %d out of range", d.Index)
+		}
+		embeddings[d.Index] = d.Embedding
+	}
+	for i := range embeddings {
+		if embeddings[i] == nil {
+			return nil, fmt.Errorf("no embedding returned for input %d", i)
+		}
+	}
+
+	if err := c.dimension.Learn(len(embeddings[0])); err != nil {
+		return nil, err
+	}
+	for i := range embeddings {
+		if len(embeddings[i]) != len(embeddings[0]) {
+			return nil, fmt.Errorf("embedding %d has %d dimensions, expected %d", i, len(embeddings[i]), len(embeddings[0]))
+		}
 	}
 
-	return embResp.Data[0].Embedding, nil
+	return embeddings, nil
+}
+
+// returns the embedding model name
+func (c *Client) Model() string {
+	return c.config.Model
 }
 
-//  returns the dimension of embeddings for the model
-func (c *Client) GetEmbeddingDimension() int {
-	return 1536
+// returns the dimension of embeddings for the model, without calling the API.
+// learned size wins, then the requested size, then the known model size.
+// 0 for an unknown model that has not answered yet, see ProbeDimension.
+func (c *Client) Dimension() int {
+	learned := c.dimension.Get()
+	switch {
+	case learned > 0:
+		return learned
+	case c.config.Dimensions > 0:
+		return c.config.Dimensions
+	}
+	return modelDimensions[c.config.Model]
+}
+
+// returns the dimension of embeddings for the model, embedding a probe text
+// when it is not known yet. the size is remembered once the API answers.
+func (c *Client) ProbeDimension() (int, error) {
+	if n := c.Dimension(); n > 0 {
+		return n, nil
+	}
+	return c.dimension.Probe(c)
 }
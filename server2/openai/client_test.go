@@ -0,0 +1,197 @@
+package openai
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestChunkTextsByItemCount(t *testing.T) {
+	texts := make([]string, maxBatchItems*2+1)
+	for i := range texts {
+		texts[i] = "pizza"
+	}
+
+	batches := chunkTexts(texts)
+	if len(batches) != 3 {
+		t.Fatalf("Expected 3 batches, got %d", len(batches))
+	}
+	if len(batches[0]) != maxBatchItems || len(batches[2]) != 1 {
+		t.Errorf("Unexpected batch sizes %d, %d", len(batches[0]), len(batches[2]))
+	}
+}
+
+func TestChunkTextsByTokenBudget(t *testing.T) {
+	long := strings.Repeat("a", maxInputTokens*4)
+	count := maxBatchTokens/maxInputTokens + 1
+
+	texts := make([]string, count)
+	for i := range texts {
+		texts[i] = long
+	}
+
+	batches := chunkTexts(texts)
+	if len(batches) != 2 {
+		t.Fatalf("Expected token budget to split into 2 batches, got %d", len(batches))
+	}
+
+	total := 0
+	for _, b := range batches {
+		total += len(b)
+	}
+	if total != count {
+		t.Errorf("Expected %d texts across batches, got %d", count, total)
+	}
+}
+
+func TestChunkTextsEmpty(t *testing.T) {
+	if batches := chunkTexts(nil); len(batches) != 0 {
+		t.Errorf("Expected no batches, got %d", len(batches))
+	}
+}
+
+func TestEmbeddingsURL(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		want string
+	}{
+		{
+			name: "openai",
+			cfg:  Config{BaseURL: DefaultBaseURL, Model: DefaultModel},
+			want: "https://api.openai.com/v1/embeddings",
+		},
+		{
+			name: "azure",
+			cfg:  Config{BaseURL: "https://res.openai.azure.com", Model: "emb-small", APIVersion: "2024-02-01"},
+			want: "https://res.openai.azure.com/openai/deployments/emb-small/embeddings?api-version=2024-02-01",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := embeddingsURL(tt.cfg); got != tt.want {
+				t.Errorf("embeddingsURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequiresKeyOnlyForDefaultBaseURL(t *testing.T) {
+	if _, err := NewClientWithConfig(Config{}); err == nil {
+		t.Error("Expected an error without API key for api.openai.com")
+	}
+	if _, err := NewClientWithConfig(Config{BaseURL: "http://localhost:8080/v1"}); err != nil {
+		t.Errorf("Local servers should not need a key, got %v", err)
+	}
+}
+
+func TestSendsConfiguredRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req EmbeddingRequest
+		json.NewDecoder(r.Body).Decode(&req)
+
+		if r.URL.Path != "/openai/deployments/my-emb/embeddings" || r.URL.Query().Get("api-version") != "2024-02-01" {
+			t.Errorf("Unexpected URL %s", r.URL)
+		}
+		if r.Header.Get("api-key") != "azure-key" || r.Header.Get("Authorization") != "" {
+			t.Error("Azure should authenticate with the api-key header")
+		}
+		if r.Header.Get("OpenAI-Organization") != "org-1" {
+			t.Error("Missing organization header")
+		}
+		if req.Dimensions != 4 {
+			t.Errorf("Expected dimensions=4 in request, got %d", req.Dimensions)
+		}
+		w.Write([]byte(`{"data":[{"index":0,"embedding":[1,0,0,0]}]}`))
+	}))
+	defer server.Close()
+
+	c, err := NewClientWithConfig(Config{
+		APIKey:       "azure-key",
+		Model:        "my-emb",
+		BaseURL:      server.URL,
+		Organization: "org-1",
+		APIVersion:   "2024-02-01",
+		Dimensions:   4,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.GetEmbedding("pizza"); err != nil {
+		t.Fatalf("GetEmbedding() error = %v", err)
+	}
+}
+
+func TestLearnsDimensionFromResponse(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write([]byte(`{"data":[{"index":0,"embedding":[0.1,0.2,0.3]}]}`))
+	}))
+	defer server.Close()
+
+	c, err := NewClientWithConfig(Config{Model: "local-model", BaseURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d := c.Dimension(); d != 0 || calls != 0 {
+		t.Errorf("Dimension() = %d after %d calls, want 0 without asking the API", d, calls)
+	}
+	if d, err := c.ProbeDimension(); d != 3 || err != nil {
+		t.Errorf("ProbeDimension() = %d, %v, want 3 from probe", d, err)
+	}
+	if d := c.Dimension(); d != 3 || calls != 1 {
+		t.Errorf("Dimension should be remembered, got %d after %d calls", d, calls)
+	}
+}
+
+func TestProbeDimensionFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	c, err := NewClientWithConfig(Config{Model: "local-model", BaseURL: server.URL, MaxRetries: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d, err := c.ProbeDimension(); err == nil {
+		t.Errorf("ProbeDimension() = %d, want an error when the API fails", d)
+	}
+}
+
+func TestKnownModelDimension(t *testing.T) {
+	c, err := NewClientWithConfig(Config{APIKey: "k", Model: "text-embedding-3-large"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := c.Dimension(); d != 3072 {
+		t.Errorf("Dimension() = %d, want 3072", d)
+	}
+}
+
+func TestConfigFromEnvMaxRetries(t *testing.T) {
+	t.Setenv("OPENAI_MAX_RETRIES", "0")
+	if cfg := ConfigFromEnv(); cfg.MaxRetries >= 0 {
+		t.Errorf("OPENAI_MAX_RETRIES=0 should disable retries, got MaxRetries %d", cfg.MaxRetries)
+	}
+
+	t.Setenv("OPENAI_MAX_RETRIES", "2")
+	if cfg := ConfigFromEnv(); cfg.MaxRetries != 2 {
+		t.Errorf("MaxRetries = %d, want 2", cfg.MaxRetries)
+	}
+
+	t.Setenv("OPENAI_MAX_RETRIES", "")
+	c, err := NewClientWithConfig(Config{BaseURL: "http://localhost", MaxRetries: ConfigFromEnv().MaxRetries})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.config.MaxRetries != DefaultMaxRetries {
+		t.Errorf("Unset OPENAI_MAX_RETRIES should give %d retries, got %d", DefaultMaxRetries, c.config.MaxRetries)
+	}
+}
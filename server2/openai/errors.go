@@ -0,0 +1,97 @@
+package openai
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// error returned by the API, parsed from its {"error":{...}} body
+type APIError struct {
+	StatusCode int
+	Type       string `json:"type"`
+	Code       string `json:"code"`
+	Param      string `json:"param"`
+	Message    string `json:"message"`
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("openai API error %d (%s): %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("openai API error %d: %s", e.StatusCode, e.Message)
+}
+
+// true when the account is out of credits, retrying won't help
+func (e *APIError) IsQuota() bool {
+	return e.Code == "insufficient_quota" || e.Type == "insufficient_quota"
+}
+
+// true when the key is missing, invalid or lacks permission
+func (e *APIError) IsAuth() bool {
+	return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+}
+
+// true when the same request may succeed later (rate limits, server errors)
+func (e *APIError) IsTransient() bool {
+	if e.IsQuota() {
+		return false
+	}
+	switch {
+	case e.StatusCode == http.StatusTooManyRequests,
+		e.StatusCode == http.StatusRequestTimeout,
+		e.StatusCode == http.StatusConflict,
+		e.StatusCode >= 500:
+		return true
+	}
+	return false
+}
+
+// builds an APIError from a non-200 response
+func parseAPIError(resp *http.Response, body []byte) *APIError {
+	var payload struct {
+		Error *APIError `json:"error"`
+	}
+
+	apiErr := &APIError{}
+	if err := json.Unmarshal(body, &payload); err == nil && payload.Error != nil {
+		apiErr = payload.Error
+	} else {
+		apiErr.Message = string(body)
+	}
+
+	apiErr.StatusCode = resp.StatusCode
+	apiErr.RetryAfter = parseRetryAfter(resp.Header)
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(resp.StatusCode)
+	}
+	return apiErr
+}
+
+// reads retry-after-ms or Retry-After (seconds or http date)
+func parseRetryAfter(h http.Header) time.Duration {
+	if ms := h.Get("retry-after-ms"); ms != "" {
+		var n float64
+		if _, err := fmt.Sscanf(ms, "%g", &n); err == nil && n > 0 {
+			return time.Duration(n * float64(time.Millisecond))
+		}
+	}
+
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+
+	var secs float64
+	if _, err := fmt.Sscanf(v, "%g", &secs); err == nil && secs > 0 {
+		return time.Duration(secs * float64(time.Second))
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
@@ -0,0 +1,67 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// decides whether an error from one attempt is worth retrying
+func isRetryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.IsTransient()
+	}
+
+	// network failures, timeouts of a single attempt, broken bodies
+	var reqErr *requestError
+	return errors.As(err, &reqErr)
+}
+
+// wait before the given retry (1-based), honoring Retry-After when the server
+// sent one, but never longer than MaxBackoff
+func (c *Client) backoff(attempt int, err error) time.Duration {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		if apiErr.RetryAfter > c.config.MaxBackoff {
+			return c.config.MaxBackoff
+		}
+		return apiErr.RetryAfter
+	}
+
+	d := c.config.BaseBackoff << (attempt - 1)
+	if d <= 0 || d > c.config.MaxBackoff {
+		d = c.config.MaxBackoff
+	}
+
+	// equal jitter: half fixed, half random
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// sleeps for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// failure to reach the API or read its answer
+type requestError struct {
+	err error
+}
+
+func (e *requestError) Error() string {
+	return e.err.Error()
+}
+
+func (e *requestError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,179 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	c, err := NewClientWithConfig(Config{
+		APIKey:      "test-key",
+		MaxRetries:  3,
+		BaseBackoff: time.Millisecond,
+		MaxBackoff:  5 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.url = server.URL
+	return c
+}
+
+const okBody = `{"data":[{"index":0,"embedding":[0.1,0.2,0.3]}]}`
+
+func TestRetriesTransientErrors(t *testing.T) {
+	var calls int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error":{"message":"boom","type":"server_error"}}`))
+			return
+		}
+		w.Write([]byte(okBody))
+	})
+
+	vec, err := c.GetEmbedding("pizza")
+	if err != nil {
+		t.Fatalf("GetEmbedding() error = %v", err)
+	}
+	if len(vec) != 3 || calls != 3 {
+		t.Errorf("Expected 3 dims after 3 calls, got %d dims after %d calls", len(vec), calls)
+	}
+}
+
+func TestHonorsRetryAfter(t *testing.T) {
+	var calls int32
+	var first time.Time
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			first = time.Now()
+			w.Header().Set("Retry-After", "0.05")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error":{"message":"slow down","type":"requests","code":"rate_limit_exceeded"}}`))
+			return
+		}
+		if time.Since(first) < 50*time.Millisecond {
+			t.Error("Retried before Retry-After elapsed")
+		}
+		w.Write([]byte(okBody))
+	})
+	c.config.MaxBackoff = time.Second
+
+	if _, err := c.GetEmbedding("pizza"); err != nil {
+		t.Fatalf("GetEmbedding() error = %v", err)
+	}
+}
+
+func TestCapsRetryAfter(t *testing.T) {
+	c := newTestClient(t, nil)
+	err := &APIError{StatusCode: http.StatusTooManyRequests, RetryAfter: time.Hour}
+	if d := c.backoff(1, err); d != c.config.MaxBackoff {
+		t.Errorf("backoff() = %v, want Retry-After capped at %v", d, c.config.MaxBackoff)
+	}
+}
+
+func TestDoesNotRetryAuthOrQuota(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		check  func(*APIError) bool
+	}{
+		{
+			name:   "auth",
+			status: http.StatusUnauthorized,
+			body:   `{"error":{"message":"bad key","type":"invalid_request_error","code":"invalid_api_key"}}`,
+			check:  (*APIError).IsAuth,
+		},
+		{
+			name:   "quota",
+			status: http.StatusTooManyRequests,
+			body:   `{"error":{"message":"no credits","type":"insufficient_quota","code":"insufficient_quota"}}`,
+			check:  (*APIError).IsQuota,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+
+			_, err := c.GetEmbedding("pizza")
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("Expected *APIError, got %v", err)
+			}
+			if !tt.check(apiErr) || apiErr.IsTransient() {
+				t.Errorf("Unexpected classification for %+v", apiErr)
+			}
+			if calls != 1 {
+				t.Errorf("Expected no retries, got %d calls", calls)
+			}
+		})
+	}
+}
+
+func TestGivesUpAfterMaxRetries(t *testing.T) {
+	var calls int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	if _, err := c.GetEmbedding("pizza"); err == nil {
+		t.Fatal("Expected an error")
+	}
+	if calls != 4 {
+		t.Errorf("Expected 1 call + 3 retries, got %d", calls)
+	}
+}
+
+func TestContextCancelStopsRetries(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "10")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	c.config.MaxBackoff = time.Minute
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.GetEmbeddingContext(ctx, "pizza")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("Cancellation should interrupt the Retry-After wait")
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	h := http.Header{}
+	h.Set("Retry-After", "2")
+	if d := parseRetryAfter(h); d != 2*time.Second {
+		t.Errorf("parseRetryAfter(2) = %v", d)
+	}
+
+	h.Set("retry-after-ms", "150")
+	if d := parseRetryAfter(h); d != 150*time.Millisecond {
+		t.Errorf("parseRetryAfter(ms=150) = %v", d)
+	}
+
+	if d := parseRetryAfter(http.Header{}); d != 0 {
+		t.Errorf("parseRetryAfter(empty) = %v", d)
+	}
+}
@@ -0,0 +1,124 @@
+package search
+
+import (
+	"server2/models"
+	"strings"
+)
+
+// names scoring below this do not match a fuzzy query
+const FuzzyThreshold = 0.6
+
+// how well a typed query matches a dish name, from 0 to 1.
+// exact and substring matches score highest, otherwise the better of
+// trigram similarity (word order, partial words) and per-word
+// Levenshtein similarity (typos like "panner tika").
+func NameScore(query, name string) float64 {
+	q, n := models.NormalizeName(query), models.NormalizeName(name)
+	switch {
+	case q == "" || n == "":
+		return 0
+	case q == n:
+		return 1
+	case strings.Contains(n, q):
+		return 0.9
+	}
+
+	score := trigramSimilarity(q, n)
+	if s := wordSimilarity(q, n); s > score {
+		score = s
+	}
+	return score
+}
+
+// best NameScore over a food's name and aliases
+func FoodNameScore(query string, food *models.Food) float64 {
+	best := NameScore(query, food.Name)
+	for _, alias := range food.Aliases {
+		if s := NameScore(query, alias); s > best {
+			best = s
+		}
+	}
+	return best
+}
+
+// dice coefficient over the padded character trigrams of a and b
+func trigramSimilarity(a, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+	shared := 0
+	for t := range ta {
+		if tb[t] {
+			shared++
+		}
+	}
+	return 2 * float64(shared) / float64(len(ta)+len(tb))
+}
+
+func trigrams(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(s) {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			set[string(padded[i:i+3])] = true
+		}
+	}
+	return set
+}
+
+// average over the query words of how close each is to its best matching name word
+func wordSimilarity(query, name string) float64 {
+	qWords, nWords := strings.Fields(query), strings.Fields(name)
+	if len(qWords) == 0 || len(nWords) == 0 {
+		return 0
+	}
+
+	var total float64
+	for _, q := range qWords {
+		best := 0.0
+		for _, n := range nWords {
+			if s := levenshteinSimilarity(q, n); s > best {
+				best = s
+			}
+		}
+		total += best
+	}
+	return total / float64(len(qWords))
+}
+
+// 1 - edit distance / length of the longer word
+func levenshteinSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(Levenshtein(a, b))/float64(longest)
+}
+
+// edit distance between two strings (insertions, deletions, substitutions)
+func Levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
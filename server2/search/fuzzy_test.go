@@ -0,0 +1,57 @@
+package search
+
+import (
+	"server2/models"
+	"testing"
+)
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"paneer", "paneer", 0},
+		{"panner", "paneer", 1},
+		{"tika", "tikka", 1},
+		{"", "dal", 3},
+		{"kitten", "sitting", 3},
+	}
+	for _, tt := range tests {
+		if got := Levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("Levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestNameScore(t *testing.T) {
+	tests := []struct {
+		query, name string
+		match       bool
+	}{
+		{"Paneer Tikka", "paneer tikka", true},
+		{"tikka", "Paneer Tikka", true},
+		{"panner tika", "Paneer Tikka", true},
+		{"chiken", "Butter Chicken", true},
+		{"tikka paneer", "Paneer Tikka", true},
+		{"sushi", "Paneer Tikka", false},
+		{"", "Paneer Tikka", false},
+	}
+	for _, tt := range tests {
+		score := NameScore(tt.query, tt.name)
+		if (score >= FuzzyThreshold) != tt.match {
+			t.Errorf("NameScore(%q, %q) = %.2f, match want %v", tt.query, tt.name, score, tt.match)
+		}
+	}
+
+	if NameScore("paneer tikka", "Paneer Tikka") <= NameScore("panner tika", "Paneer Tikka") {
+		t.Error("Exact matches should outscore typos")
+	}
+}
+
+func TestFoodNameScoreUsesAliases(t *testing.T) {
+	food := &models.Food{Name: "Butter Chicken", Aliases: []string{"Murgh Makhani"}}
+	if FoodNameScore("makhani", food) < FuzzyThreshold {
+		t.Error("Aliases should be searchable")
+	}
+}
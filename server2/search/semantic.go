@@ -0,0 +1,82 @@
+package search
+
+import (
+	"fmt"
+	"server2/engine"
+	"server2/models"
+	"server2/store"
+	"sort"
+)
+
+// default and maximum number of search results
+const (
+	DefaultK = 10
+	MaxK     = 50
+)
+
+// a food and how well it matches a query
+type Result struct {
+	Food  *models.FoodWithEmbedding
+	Score float64
+}
+
+// what to return from a search
+type Options struct {
+	K       int // top-k results, DefaultK when zero
+	Filter  models.Filter
+	Weights engine.HybridWeights // used by Hybrid, the defaults when zero
+}
+
+// embeds the query and ranks the catalog's foods by cosine similarity to it.
+// only foods passing the filter are ranked. reuses the food vectors in memory,
+// the embedder is called once for the query.
+func Semantic(foodStore *store.FoodStore, query string, opts Options) ([]Result, error) {
+	return rank(foodStore, query, opts, func(food *models.FoodWithEmbedding, cosine float64) float64 {
+		return cosine
+	})
+}
+
+// like Semantic, but blends cosine similarity with the BM25 keyword score
+// (scaled by the best match) using opts.Weights, so an exact dish name or
+// ingredient in the query is not drowned out by vaguely similar foods
+func Hybrid(foodStore *store.FoodStore, query string, opts Options) ([]Result, error) {
+	lexical := engine.NormalizeScores(foodStore.BM25().Scores(query))
+	return rank(foodStore, query, opts, func(food *models.FoodWithEmbedding, cosine float64) float64 {
+		return opts.Weights.Blend(cosine, lexical[food.ID])
+	})
+}
+
+// embeds the query and scores every food passing the filter
+func rank(foodStore *store.FoodStore, query string, opts Options, score func(*models.FoodWithEmbedding, float64) float64) ([]Result, error) {
+	foods, mode := foodStore.Snapshot()
+	vector, err := foodStore.EmbedQueryIn(mode, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+
+	results := make([]Result, 0, len(foods))
+	for i := range foods {
+		if !opts.Filter.Matches(&foods[i].Food) {
+			continue
+		}
+		results = append(results, Result{
+			Food:  &foods[i],
+			Score: score(&foods[i], engine.CosineSimilarity(vector, foods[i].Embedding)),
+		})
+	}
+	return topK(results, opts.K), nil
+}
+
+// sorts results best first and keeps the first k
+func topK(results []Result, k int) []Result {
+	if k <= 0 {
+		k = DefaultK
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if len(results) > k {
+		results = results[:k]
+	}
+	return results
+}
@@ -0,0 +1,88 @@
+package search
+
+import (
+	"os"
+	"path/filepath"
+	"server2/embedding"
+	"server2/engine"
+	"server2/models"
+	"server2/store"
+	"testing"
+)
+
+const testFoods = `[
+	{"id": "1", "name": "Butter Chicken", "description": "Creamy tomato curry with tender chicken", "allergens": ["dairy"], "spice_level": 2},
+	{"id": "2", "name": "Sushi Platter", "description": "Raw fish on vinegared rice", "allergens": ["fish"]},
+	{"id": "3", "name": "Vindaloo", "description": "Fiery hot and spicy curry with chilli and vinegar", "spice_level": 5},
+	{"id": "4", "name": "Tomato Soup", "description": "Warm creamy tomato soup", "vegetarian": true, "allergens": ["dairy"]}
+]`
+
+func newTestStore(t *testing.T) *store.FoodStore {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "foods.json")
+	if err := os.WriteFile(path, []byte(testFoods), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	foodStore, err := store.NewFoodStore(path, embedding.NewHashEmbedder(256))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return foodStore
+}
+
+func TestSemanticSearch(t *testing.T) {
+	foodStore := newTestStore(t)
+
+	results, err := Semantic(foodStore, "hot spicy curry", Options{K: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected top 2 results, got %d", len(results))
+	}
+	if results[0].Food.Name != "Vindaloo" {
+		t.Errorf("Best match for a spicy curry should be Vindaloo, got %s", results[0].Food.Name)
+	}
+	if results[0].Score < results[1].Score {
+		t.Error("Results should be sorted by score")
+	}
+}
+
+func TestSemanticSearchFilters(t *testing.T) {
+	foodStore := newTestStore(t)
+
+	results, err := Semantic(foodStore, "creamy tomato", Options{Filter: models.Filter{Constraints: models.Constraints{Vegetarian: true}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Food.Name != "Tomato Soup" {
+		t.Errorf("Only the vegetarian soup should match, got %v", results)
+	}
+}
+
+func TestHybridSearch(t *testing.T) {
+	foodStore := newTestStore(t)
+
+	results, err := Hybrid(foodStore, "vinegar", Options{Weights: engine.HybridWeights{Lexical: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Food.Name != "Vindaloo" || results[0].Score != 1 {
+		t.Errorf("Only Vindaloo mentions vinegar, got %s (%.3f)", results[0].Food.Name, results[0].Score)
+	}
+	if results[1].Score != 0 {
+		t.Errorf("Foods without the keyword should score 0 on keywords alone, got %.3f", results[1].Score)
+	}
+
+	semantic, _ := Semantic(foodStore, "fish", Options{K: 1})
+	hybrid, err := Hybrid(foodStore, "fish", Options{K: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hybrid[0].Food.Name != "Sushi Platter" {
+		t.Errorf("Best match for fish should be the sushi, got %s", hybrid[0].Food.Name)
+	}
+	if hybrid[0].Score <= semantic[0].Score*engine.DefaultHybridWeights.Semantic {
+		t.Error("A keyword match should add to the semantic score")
+	}
+}
@@ -0,0 +1,66 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"server2/models"
+	"sync"
+	"time"
+)
+
+// catalog edit actions
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// one change to the catalog
+type AuditEntry struct {
+	Time    time.Time    `json:"time"`
+	Actor   string       `json:"actor"`                   // who authenticated, the shared token is always "admin"
+	Claimed string       `json:"claimed_actor,omitempty"` // self-reported X-Admin-User, not verified
+	Remote  string       `json:"remote,omitempty"`
+	Catalog string       `json:"catalog"`
+	Action  string       `json:"action"`
+	FoodID  string       `json:"food_id"`
+	Before  *models.Food `json:"before,omitempty"`
+	After   *models.Food `json:"after,omitempty"`
+}
+
+// append-only log of catalog changes, one json object per line
+type AuditLog struct {
+	mu   sync.Mutex
+	path string
+}
+
+// creates an audit log writing to path
+func NewAuditLog(path string) *AuditLog {
+	return &AuditLog{path: path}
+}
+
+// appends an entry, stamping the time if it is missing
+func (l *AuditLog) Record(entry AuditEntry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now().UTC()
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package store
+
+import (
+	"math"
+	"server2/embedding"
+	"server2/models"
+	"strings"
+)
+
+// standard BM25 parameters
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+
+	nameBoost = 2 // name and alias terms count this many times
+)
+
+// keyword index over food names, aliases, descriptions and tags
+type BM25Index struct {
+	postings map[string]map[string]int // term -> food ID -> term frequency
+	docLen   map[string]int
+	avgLen   float64
+}
+
+// indexes a catalog
+func NewBM25Index(foods []models.Food) *BM25Index {
+	ix := &BM25Index{
+		postings: make(map[string]map[string]int),
+		docLen:   make(map[string]int, len(foods)),
+	}
+
+	total := 0
+	for i := range foods {
+		terms := foodTerms(&foods[i])
+		ix.docLen[foods[i].ID] = len(terms)
+		total += len(terms)
+		for _, term := range terms {
+			if ix.postings[term] == nil {
+				ix.postings[term] = make(map[string]int)
+			}
+			ix.postings[term][foods[i].ID]++
+		}
+	}
+	if len(foods) > 0 {
+		ix.avgLen = float64(total) / float64(len(foods))
+	}
+	return ix
+}
+
+// BM25 score of every food matching at least one query term, by food ID
+func (ix *BM25Index) Scores(query string) map[string]float64 {
+	scores := make(map[string]float64)
+	if ix == nil || len(ix.docLen) == 0 {
+		return scores
+	}
+	n := float64(len(ix.docLen))
+
+	seen := make(map[string]bool)
+	for _, term := range QueryTerms(query) {
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+
+		docs := ix.postings[term]
+		if len(docs) == 0 {
+			continue
+		}
+		df := float64(len(docs))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for id, tf := range docs {
+			norm := 1 - bm25B + bm25B*float64(ix.docLen[id])/ix.avgLen
+			f := float64(tf)
+			scores[id] += idf * f * (bm25K1 + 1) / (f + bm25K1*norm)
+		}
+	}
+	return scores
+}
+
+// terms indexed for a food, names weighted above the rest
+func foodTerms(food *models.Food) []string {
+	var terms []string
+	names := QueryTerms(food.Name + " " + strings.Join(food.Aliases, " "))
+	for i := 0; i < nameBoost; i++ {
+		terms = append(terms, names...)
+	}
+	terms = append(terms, QueryTerms(food.Description)...)
+	terms = append(terms, QueryTerms(strings.Join(food.Tags, " "))...)
+	return terms
+}
+
+// splits text into index terms: lowercase words without stop words,
+// with a plural "s" dropped so "dumplings" finds "dumpling"
+func QueryTerms(text string) []string {
+	words := embedding.Tokenize(text)
+	for i, w := range words {
+		if len(w) > 3 && strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss") {
+			words[i] = w[:len(w)-1]
+		}
+	}
+	return words
+}
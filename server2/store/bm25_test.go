@@ -0,0 +1,44 @@
+package store
+
+import (
+	"reflect"
+	"server2/models"
+	"testing"
+)
+
+func TestQueryTerms(t *testing.T) {
+	got := QueryTerms("The Dumplings with Glass Noodles")
+	want := []string{"dumpling", "glass", "noodle"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("QueryTerms() = %v, want %v", got, want)
+	}
+}
+
+func TestBM25Scores(t *testing.T) {
+	index := NewBM25Index([]models.Food{
+		{ID: "1", Name: "Paneer Tikka", Description: "Grilled cottage cheese cubes"},
+		{ID: "2", Name: "Palak Paneer", Description: "Spinach curry"},
+		{ID: "3", Name: "Butter Chicken", Description: "Creamy curry, try it with paneer naan", Tags: []string{"curry"}},
+		{ID: "4", Name: "Sushi", Description: "Raw fish"},
+	})
+
+	scores := index.Scores("paneer")
+	if len(scores) != 3 {
+		t.Fatalf("Expected 3 foods mentioning paneer, got %v", scores)
+	}
+	if scores["1"] <= scores["3"] || scores["2"] <= scores["3"] {
+		t.Errorf("Paneer in the name should outrank paneer in the description: %v", scores)
+	}
+	if _, ok := scores["4"]; ok {
+		t.Error("Foods without the term should not be scored")
+	}
+
+	// rare terms weigh more than common ones
+	scores = index.Scores("curry fish")
+	if scores["4"] <= scores["2"] {
+		t.Errorf("The rarer term should dominate: %v", scores)
+	}
+	if len(index.Scores("the and of")) != 0 {
+		t.Error("Stop words should not match anything")
+	}
+}
@@ -0,0 +1,116 @@
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const cacheVersion = 1
+
+// on-disk embedding cache, keyed by text hash + model + dimension
+type EmbeddingCache struct {
+	path    string
+	entries map[string][]float64
+}
+
+// file layout of the cache
+type cacheFile struct {
+	Version int                  `json:"version"`
+	Entries map[string][]float64 `json:"entries"`
+}
+
+// returns the cache path that sits next to a catalog file
+func CachePath(dataPath string) string {
+	ext := filepath.Ext(dataPath)
+	return strings.TrimSuffix(dataPath, ext) + ".embeddings.json"
+}
+
+// loads the cache, a missing or unreadable file gives an empty cache
+func LoadEmbeddingCache(path string) *EmbeddingCache {
+	cache := &EmbeddingCache{
+		path:    path,
+		entries: make(map[string][]float64),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cache
+	}
+
+	var file cacheFile
+	if err := json.Unmarshal(data, &file); err != nil || file.Version != cacheVersion {
+		fmt.Printf("Ignoring embedding cache %s (corrupt or old version)\n", path)
+		return cache
+	}
+	if file.Entries != nil {
+		cache.entries = file.Entries
+	}
+	return cache
+}
+
+// builds the cache key for a text embedded by a given model
+func CacheKey(model string, dimension int, text string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%d\x00%s", model, dimension, text)))
+	return hex.EncodeToString(sum[:])
+}
+
+// returns the cached vector for a key
+func (c *EmbeddingCache) Get(key string) ([]float64, bool) {
+	vec, ok := c.entries[key]
+	return vec, ok
+}
+
+// stores a vector under a key
+func (c *EmbeddingCache) Put(key string, vec []float64) {
+	c.entries[key] = vec
+}
+
+// drops every entry not in keep, so stale models and removed foods don't pile up
+func (c *EmbeddingCache) Retain(keep map[string]bool) {
+	for key := range c.entries {
+		if !keep[key] {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// writes the cache atomically (temp file + rename)
+func (c *EmbeddingCache) Save() error {
+	data, err := json.Marshal(cacheFile{Version: cacheVersion, Entries: c.entries})
+	if err != nil {
+		return fmt.Errorf("failed to encode cache: %w", err)
+	}
+
+	if err := writeFileAtomic(c.path, data); err != nil {
+		return fmt.Errorf("failed to save cache: %w", err)
+	}
+	return nil
+}
+
+// writes to a temp file next to path and renames it over path,
+// so readers see either the old or the new file, never a partial one
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,114 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"server2/embedding"
+	"testing"
+)
+
+// counts how many texts reach the wrapped embedder
+type countingEmbedder struct {
+	inner *embedding.HashEmbedder
+	model string
+	calls int
+}
+
+func (e *countingEmbedder) GetEmbedding(text string) ([]float64, error) {
+	e.calls++
+	return e.inner.GetEmbedding(text)
+}
+
+func (e *countingEmbedder) Dimension() int {
+	return e.inner.Dimension()
+}
+
+func (e *countingEmbedder) Model() string {
+	return e.model
+}
+
+func writeFoods(t *testing.T, dir, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, "foods.json")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+const testFoods = `[
+	{"id": "1", "name": "Butter Chicken", "description": "Creamy curry"},
+	{"id": "2", "name": "Sushi", "description": "Rice and raw fish"}
+]`
+
+func TestCachePath(t *testing.T) {
+	if got := CachePath("data/foods.json"); got != "data/foods.embeddings.json" {
+		t.Errorf("CachePath() = %q", got)
+	}
+}
+
+func TestFoodStoreUsesCache(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFoods(t, dir, testFoods)
+
+	first := &countingEmbedder{inner: embedding.NewHashEmbedder(32), model: "m1"}
+	if _, err := NewFoodStore(path, first); err != nil {
+		t.Fatal(err)
+	}
+	if first.calls != 2 {
+		t.Errorf("First load should embed 2 foods, embedded %d", first.calls)
+	}
+
+	second := &countingEmbedder{inner: embedding.NewHashEmbedder(32), model: "m1"}
+	store, err := NewFoodStore(path, second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.calls != 0 {
+		t.Errorf("Second load should come from cache, embedded %d", second.calls)
+	}
+	if len(store.GetAll()[0].Embedding) != 32 {
+		t.Error("Cached embedding has wrong dimension")
+	}
+}
+
+func TestFoodStoreCacheOnlyEmbedsChanges(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFoods(t, dir, testFoods)
+
+	e := &countingEmbedder{inner: embedding.NewHashEmbedder(32), model: "m1"}
+	if _, err := NewFoodStore(path, e); err != nil {
+		t.Fatal(err)
+	}
+
+	writeFoods(t, dir, `[
+		{"id": "1", "name": "Butter Chicken", "description": "Creamy curry"},
+		{"id": "2", "name": "Sushi", "description": "Vinegared rice and raw fish"},
+		{"id": "3", "name": "Tacos", "description": "Corn tortillas"}
+	]`)
+
+	e.calls = 0
+	if _, err := NewFoodStore(path, e); err != nil {
+		t.Fatal(err)
+	}
+	if e.calls != 2 {
+		t.Errorf("Only the changed and new food should be embedded, embedded %d", e.calls)
+	}
+}
+
+func TestFoodStoreCacheInvalidatedByModel(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFoods(t, dir, testFoods)
+
+	if _, err := NewFoodStore(path, &countingEmbedder{inner: embedding.NewHashEmbedder(32), model: "m1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	other := &countingEmbedder{inner: embedding.NewHashEmbedder(32), model: "m2"}
+	if _, err := NewFoodStore(path, other); err != nil {
+		t.Fatal(err)
+	}
+	if other.calls != 2 {
+		t.Errorf("Changing the model should re-embed everything, embedded %d", other.calls)
+	}
+}
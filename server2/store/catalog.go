@@ -0,0 +1,187 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"server2/catalog"
+	"server2/models"
+	"strconv"
+)
+
+// errors returned by the catalog edit methods
+var (
+	ErrNotReady     = errors.New("catalog is still loading")
+	ErrLoadFailed   = errors.New("catalog failed to load")
+	ErrFoodNotFound = errors.New("food not found")
+	ErrFoodExists   = errors.New("a food with this id already exists")
+	ErrNameTaken    = errors.New("name or alias already used by another food")
+	ErrReadOnly     = errors.New("catalog can only be edited when it is a foods.json file, import it first")
+
+	ErrEmbedderUnavailable = errors.New("embedding provider unavailable, catalog left unchanged")
+)
+
+// adds a food to the catalog and writes it to disk.
+// foods without an ID get the next free numeric one.
+func (s *FoodStore) AddFood(food models.Food) (models.Food, error) {
+	err := s.editCatalog(func(foods []models.Food) ([]models.Food, int, error) {
+		if food.ID == "" {
+			food.ID = nextFoodID(foods)
+		}
+		if indexOfFood(foods, food.ID) >= 0 {
+			return nil, 0, ErrFoodExists
+		}
+		return append(foods, food), len(foods), nil
+	})
+	return food, err
+}
+
+// replaces the food with the given ID and writes the catalog to disk.
+// returns the food as it was before the change.
+func (s *FoodStore) UpdateFood(id string, food models.Food) (models.Food, error) {
+	var before models.Food
+	food.ID = id
+	err := s.editCatalog(func(foods []models.Food) ([]models.Food, int, error) {
+		i := indexOfFood(foods, id)
+		if i < 0 {
+			return nil, 0, ErrFoodNotFound
+		}
+		before = foods[i]
+		foods[i] = food
+		return foods, i, nil
+	})
+	return before, err
+}
+
+// removes the food with the given ID and writes the catalog to disk
+func (s *FoodStore) DeleteFood(id string) (models.Food, error) {
+	var removed models.Food
+	err := s.editCatalog(func(foods []models.Food) ([]models.Food, int, error) {
+		i := indexOfFood(foods, id)
+		if i < 0 {
+			return nil, 0, ErrFoodNotFound
+		}
+		removed = foods[i]
+		return append(foods[:i], foods[i+1:]...), -1, nil
+	})
+	return removed, err
+}
+
+// applies an edit to a copy of the catalog, validates it, saves it and swaps it in.
+// edit returns the new list and the index of the added or changed food (-1 for none).
+// unchanged foods keep their vectors, so only the edited food is embedded.
+func (s *FoodStore) editCatalog(edit func(foods []models.Food) ([]models.Food, int, error)) error {
+	if err := s.LoadError(); err != nil {
+		return err
+	}
+	if !s.Ready() {
+		return ErrNotReady
+	}
+	if !s.editable() {
+		return ErrReadOnly
+	}
+
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	current, mode := s.Snapshot()
+	foods, changed, err := edit(catalogFoods(current))
+	if err != nil {
+		return err
+	}
+	if changed >= 0 {
+		if errs := foods[changed].Validate(); len(errs) > 0 {
+			return errs
+		}
+		if nameTaken(foods, changed) {
+			return ErrNameTaken
+		}
+	}
+
+	var known map[string][]float64
+	if mode == ModeEmbedding {
+		known = make(map[string][]float64, len(current))
+		for _, item := range current {
+			known[foodText(item.Food)] = item.Embedding
+		}
+	}
+	// embed before saving, so a failed edit leaves neither file nor catalog changed
+	vectors, mode, err := s.vectorsFor(foods, known)
+	if err != nil {
+		return err
+	}
+	if err := s.saveCatalog(foods); err != nil {
+		return err
+	}
+	s.swapIn(foods, vectors, mode)
+	return nil
+}
+
+// edits are written back as canonical json, other formats would be overwritten.
+// that includes schema.org documents saved with a .json extension.
+func (s *FoodStore) editable() bool {
+	format := s.format
+	if format == "" {
+		format, _ = catalog.DetectFormat(s.dataPath)
+	}
+	if format != catalog.FormatJSON {
+		return false
+	}
+	data, err := os.ReadFile(s.dataPath)
+	return err == nil && !catalog.IsJSONLD(data)
+}
+
+// writes the catalog to the data file atomically
+func (s *FoodStore) saveCatalog(foods []models.Food) error {
+	data, err := catalog.MarshalJSON(foods)
+	if err != nil {
+		return fmt.Errorf("failed to encode foods: %w", err)
+	}
+	if err := writeFileAtomic(s.dataPath, data); err != nil {
+		return fmt.Errorf("failed to save foods file: %w", err)
+	}
+	return nil
+}
+
+func indexOfFood(foods []models.Food, id string) int {
+	for i := range foods {
+		if foods[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// one more than the largest numeric ID in the catalog
+func nextFoodID(foods []models.Food) string {
+	max := 0
+	for _, food := range foods {
+		if n, err := strconv.Atoi(food.ID); err == nil && n > max {
+			max = n
+		}
+	}
+	return strconv.Itoa(max + 1)
+}
+
+// true if the name or an alias of foods[i] matches the name or an alias of another food
+func nameTaken(foods []models.Food, i int) bool {
+	names := map[string]bool{NormalizeName(foods[i].Name): true}
+	for _, alias := range foods[i].Aliases {
+		names[NormalizeName(alias)] = true
+	}
+
+	for j, other := range foods {
+		if j == i {
+			continue
+		}
+		if names[NormalizeName(other.Name)] {
+			return true
+		}
+		for _, alias := range other.Aliases {
+			if names[NormalizeName(alias)] {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,155 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"server2/embedding"
+	"server2/models"
+	"testing"
+)
+
+func readCatalog(t *testing.T, path string) []models.Food {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var foods []models.Food
+	if err := json.Unmarshal(data, &foods); err != nil {
+		t.Fatalf("Catalog on disk is not valid json: %v", err)
+	}
+	return foods
+}
+
+func TestCatalogEdits(t *testing.T) {
+	path := writeFoods(t, t.TempDir(), testFoods)
+	e := &countingEmbedder{inner: embedding.NewHashEmbedder(16), model: "m1"}
+	store, err := NewFoodStore(path, e)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e.calls = 0
+	added, err := store.AddFood(models.Food{Name: "Tacos", Description: "Corn tortillas"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added.ID != "3" {
+		t.Errorf("New food should get the next numeric id, got %q", added.ID)
+	}
+	if e.calls != 1 {
+		t.Errorf("Only the new food should be embedded, embedded %d", e.calls)
+	}
+	if store.GetByName("tacos") == nil || len(readCatalog(t, path)) != 3 {
+		t.Error("Added food should be served and written to disk")
+	}
+
+	before, err := store.UpdateFood("2", models.Food{Name: "Sushi", Description: "Nigiri and maki", PriceRange: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if before.Description != "Rice and raw fish" {
+		t.Errorf("UpdateFood should return the old food, got %+v", before)
+	}
+	if food := store.GetByID("2"); food == nil || food.PriceRange != 4 {
+		t.Errorf("GetByID(2) should return the updated food, got %v", food)
+	}
+
+	if _, err := store.DeleteFood("1"); err != nil {
+		t.Fatal(err)
+	}
+	if store.GetByID("1") != nil {
+		t.Error("Deleted food should be gone")
+	}
+	onDisk := readCatalog(t, path)
+	if len(onDisk) != 2 || onDisk[0].Description != "Nigiri and maki" {
+		t.Errorf("Catalog on disk out of date: %+v", onDisk)
+	}
+}
+
+func TestCatalogEditErrors(t *testing.T) {
+	path := writeFoods(t, t.TempDir(), testFoods)
+	store, err := NewFoodStore(path, embedding.NewHashEmbedder(16))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		edit func() error
+		want error
+	}{
+		{"duplicate id", func() error {
+			_, err := store.AddFood(models.Food{ID: "1", Name: "Tacos"})
+			return err
+		}, ErrFoodExists},
+		{"duplicate name", func() error {
+			_, err := store.AddFood(models.Food{Name: "butter chicken", Description: "Another creamy curry"})
+			return err
+		}, ErrNameTaken},
+		{"alias clashes with name", func() error {
+			_, err := store.UpdateFood("2", models.Food{Name: "Sushi", Description: "Rice and raw fish", Aliases: []string{"Butter Chicken"}})
+			return err
+		}, ErrNameTaken},
+		{"unknown food", func() error {
+			_, err := store.DeleteFood("99")
+			return err
+		}, ErrFoodNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.edit(); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	var invalid models.ValidationErrors
+	if _, err := store.AddFood(models.Food{Name: "Soup", Course: "brunch"}); !errors.As(err, &invalid) {
+		t.Errorf("Invalid course should fail validation, got %v", err)
+	}
+	if len(readCatalog(t, path)) != 2 || len(store.GetAll()) != 2 {
+		t.Error("Rejected edits should not touch the catalog")
+	}
+}
+
+func TestCatalogEditsNeedJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "menu.csv")
+	os.WriteFile(path, []byte("name,description\nDal,Yellow lentils with cumin\n"), 0o644)
+
+	store, err := NewFoodStore(path, embedding.NewHashEmbedder(16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if store.GetByName("dal") == nil {
+		t.Fatal("CSV catalog should load")
+	}
+	if _, err := store.AddFood(models.Food{Name: "Rice", Description: "Steamed basmati"}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Editing a CSV catalog should fail with ErrReadOnly, got %v", err)
+	}
+}
+
+func TestCatalogEditsRejectJSONLD(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "menu.json")
+	menu := `{"@context": "https://schema.org", "@type": "Menu", "hasMenuItem": [
+		{"@type": "MenuItem", "identifier": "dal", "name": "Dal", "description": "Yellow lentils with cumin"}
+	]}`
+	os.WriteFile(path, []byte(menu), 0o644)
+
+	store, err := NewFoodStore(path, embedding.NewHashEmbedder(16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if store.GetByName("dal") == nil {
+		t.Fatal("JSON-LD saved as .json should load")
+	}
+	if _, err := store.AddFood(models.Food{Name: "Rice", Description: "Steamed basmati"}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Editing a JSON-LD catalog should fail with ErrReadOnly, got %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != menu {
+		t.Error("The JSON-LD document should be left alone")
+	}
+}
@@ -0,0 +1,110 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"server2/catalog"
+	"server2/embedding"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ID of the catalog read from FOODS_PATH, used when a session names none
+const DefaultCatalog = "default"
+
+var (
+	ErrCatalogNotFound = errors.New("catalog not found")
+	ErrCatalogExists   = errors.New("catalog already exists")
+)
+
+// catalog IDs end up in URLs and QR codes, keep them plain
+var catalogIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// named catalogs, each with its own foods and embeddings
+type Catalogs struct {
+	mu     sync.RWMutex
+	stores map[string]*FoodStore
+}
+
+// creates a registry holding the default catalog
+func NewCatalogs(defaultStore *FoodStore) *Catalogs {
+	return &Catalogs{
+		stores: map[string]*FoodStore{DefaultCatalog: defaultStore},
+	}
+}
+
+// registers a catalog under an ID
+func (c *Catalogs) Add(id string, foodStore *FoodStore) error {
+	if !catalogIDPattern.MatchString(id) {
+		return fmt.Errorf("invalid catalog id %q: use lowercase letters, digits, - and _", id)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.stores[id]; ok {
+		return fmt.Errorf("%w: %s", ErrCatalogExists, id)
+	}
+	c.stores[id] = foodStore
+	return nil
+}
+
+// returns a catalog by ID, "" is the default catalog, nil if unknown
+func (c *Catalogs) Get(id string) *FoodStore {
+	if id == "" {
+		id = DefaultCatalog
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.stores[id]
+}
+
+// returns the default catalog
+func (c *Catalogs) Default() *FoodStore {
+	return c.Get(DefaultCatalog)
+}
+
+// all catalog IDs, sorted
+func (c *Catalogs) IDs() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	ids := make([]string, 0, len(c.stores))
+	for id := range c.stores {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// registers every catalog file in dir, named after the file (cafe-42.csv -> "cafe-42").
+// files whose name is not a valid or free catalog id are logged and skipped.
+// the stores are not loaded yet, call Load on each.
+func (c *Catalogs) OpenDir(dir string, embedder embedding.Embedder) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read catalogs dir: %w", err)
+	}
+
+	var ids []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || strings.HasSuffix(name, ".embeddings.json") {
+			continue
+		}
+		if _, err := catalog.DetectFormat(name); err != nil {
+			continue
+		}
+
+		id := strings.TrimSuffix(name, filepath.Ext(name))
+		if err := c.Add(id, OpenFoodStore(filepath.Join(dir, name), embedder)); err != nil {
+			fmt.Printf("Warning: skipping catalog file %s: %v\n", name, err)
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,57 @@
+package store
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"server2/embedding"
+	"testing"
+)
+
+func TestCatalogsOpenDir(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"cafe-42.json", "cafe-42.embeddings.json", "tapas_bar.csv", "notes.txt", ".hidden.json", "Bad Name.json", "default.yaml"} {
+		os.WriteFile(filepath.Join(dir, name), []byte("[]"), 0o644)
+	}
+	os.Mkdir(filepath.Join(dir, "old"), 0o755)
+
+	defaultStore := OpenFoodStore("foods.json", embedding.NewHashEmbedder(16))
+	catalogs := NewCatalogs(defaultStore)
+	ids, err := catalogs.OpenDir(dir, embedding.NewHashEmbedder(16))
+	if err != nil {
+		t.Fatalf("Bad file names should be skipped, got %v", err)
+	}
+
+	if !reflect.DeepEqual(ids, []string{"cafe-42", "tapas_bar"}) {
+		t.Errorf("OpenDir() = %v", ids)
+	}
+	if !reflect.DeepEqual(catalogs.IDs(), []string{"cafe-42", DefaultCatalog, "tapas_bar"}) {
+		t.Errorf("IDs() = %v", catalogs.IDs())
+	}
+	if catalogs.Get("") != defaultStore || catalogs.Default() != defaultStore {
+		t.Error("Empty id should resolve to the default catalog")
+	}
+	if catalogs.Get("nope") != nil {
+		t.Error("Unknown catalogs should be nil")
+	}
+}
+
+func TestCatalogsAdd(t *testing.T) {
+	catalogs := NewCatalogs(OpenFoodStore("foods.json", embedding.NewHashEmbedder(16)))
+	other := OpenFoodStore("other.json", embedding.NewHashEmbedder(16))
+
+	if err := catalogs.Add(DefaultCatalog, other); !errors.Is(err, ErrCatalogExists) {
+		t.Errorf("Adding the default id again should fail with ErrCatalogExists, got %v", err)
+	}
+	for _, id := range []string{"", "Cafe", "cafe 42", "../etc"} {
+		if err := catalogs.Add(id, other); err == nil {
+			t.Errorf("Add(%q) should reject the id", id)
+		}
+	}
+
+	sessions := NewSessionStore(catalogs)
+	if _, err := sessions.Create(SessionOptions{Catalog: "nope"}); !errors.Is(err, ErrCatalogNotFound) {
+		t.Errorf("Sessions on unknown catalogs should fail, got %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package store
+
+import (
+	"math"
+	"server2/embedding"
+	"server2/models"
+	"sort"
+)
+
+// clusters built at load for cold-start cards, see SetColdStartClusters
+const DefaultColdStartClusters = 8
+
+// groups foods into k clusters spread over the vector space.
+// centers are picked by farthest-point sampling: the food closest to the
+// catalog centroid, then each time the food farthest from every center so far.
+// each food joins its nearest center. clusters hold food IDs, center first,
+// then by similarity to the center.
+func clusterFoods(foods []models.FoodWithEmbedding, k int) [][]string {
+	if k > len(foods) {
+		k = len(foods)
+	}
+	if k <= 0 {
+		return nil
+	}
+
+	centers := []int{closestTo(foods, centroid(foods))}
+	isCenter := map[int]int{centers[0]: 0}
+	// nearest[i] is the similarity of food i to its closest center so far
+	nearest := make([]float64, len(foods))
+	for i := range foods {
+		nearest[i] = embedding.CosineSimilarity(foods[i].Embedding, foods[centers[0]].Embedding)
+	}
+	for len(centers) < k {
+		next := -1
+		for i := range foods {
+			if _, ok := isCenter[i]; !ok && (next < 0 || nearest[i] < nearest[next]) {
+				next = i
+			}
+		}
+		isCenter[next] = len(centers)
+		centers = append(centers, next)
+		for i := range foods {
+			nearest[i] = math.Max(nearest[i], embedding.CosineSimilarity(foods[i].Embedding, foods[next].Embedding))
+		}
+	}
+
+	type member struct {
+		id  string
+		sim float64
+	}
+	members := make([][]member, k)
+	for i := range foods {
+		if c, ok := isCenter[i]; ok {
+			members[c] = append(members[c], member{foods[i].ID, math.Inf(1)})
+			continue
+		}
+		best, bestSim := 0, math.Inf(-1)
+		for c, center := range centers {
+			if sim := embedding.CosineSimilarity(foods[i].Embedding, foods[center].Embedding); sim > bestSim {
+				best, bestSim = c, sim
+			}
+		}
+		members[best] = append(members[best], member{foods[i].ID, bestSim})
+	}
+
+	clusters := make([][]string, k)
+	for c := range members {
+		sort.SliceStable(members[c], func(i, j int) bool { return members[c][i].sim > members[c][j].sim })
+		for _, m := range members[c] {
+			clusters[c] = append(clusters[c], m.id)
+		}
+	}
+	return clusters
+}
+
+// mean of all food vectors
+func centroid(foods []models.FoodWithEmbedding) []float64 {
+	mean := make([]float64, len(foods[0].Embedding))
+	for _, f := range foods {
+		for i := range mean {
+			if i < len(f.Embedding) {
+				mean[i] += f.Embedding[i]
+			}
+		}
+	}
+	return mean
+}
+
+// index of the food most similar to v
+func closestTo(foods []models.FoodWithEmbedding, v []float64) int {
+	best, bestSim := 0, math.Inf(-1)
+	for i := range foods {
+		if sim := embedding.CosineSimilarity(foods[i].Embedding, v); sim > bestSim {
+			best, bestSim = i, sim
+		}
+	}
+	return best
+}
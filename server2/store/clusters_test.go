@@ -0,0 +1,47 @@
+package store
+
+import (
+	"server2/models"
+	"sort"
+	"testing"
+)
+
+func TestClusterFoods(t *testing.T) {
+	foods := []models.FoodWithEmbedding{
+		{Food: models.Food{ID: "curry-1"}, Embedding: []float64{1, 0.1, 0}},
+		{Food: models.Food{ID: "curry-2"}, Embedding: []float64{1, 0, 0.1}},
+		{Food: models.Food{ID: "sushi-1"}, Embedding: []float64{0, 1, 0.1}},
+		{Food: models.Food{ID: "curry-3"}, Embedding: []float64{0.9, 0.1, 0.1}},
+		{Food: models.Food{ID: "cake-1"}, Embedding: []float64{0.1, 0, 1}},
+		{Food: models.Food{ID: "sushi-2"}, Embedding: []float64{0.1, 1, 0}},
+	}
+
+	clusters := clusterFoods(foods, 3)
+	if len(clusters) != 3 {
+		t.Fatalf("Expected 3 clusters, got %d", len(clusters))
+	}
+
+	var groups []string
+	seen := 0
+	for _, c := range clusters {
+		prefix := c[0][:len(c[0])-2]
+		for _, id := range c {
+			if id[:len(id)-2] != prefix {
+				t.Errorf("Cluster %v mixes dishes", c)
+			}
+		}
+		groups = append(groups, prefix)
+		seen += len(c)
+	}
+	sort.Strings(groups)
+	if seen != len(foods) || groups[0] != "cake" || groups[1] != "curry" || groups[2] != "sushi" {
+		t.Errorf("Expected one cluster each for cake, curry and sushi, got %v", clusters)
+	}
+
+	if got := clusterFoods(foods, 10); len(got) != len(foods) {
+		t.Errorf("k above the catalog size should give one cluster per food, got %d", len(got))
+	}
+	if clusterFoods(foods, 0) != nil || clusterFoods(nil, 3) != nil {
+		t.Error("No clusters expected for k=0 or an empty catalog")
+	}
+}
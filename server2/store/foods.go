@@ -1,74 +1,481 @@
 package store
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
+	"server2/catalog"
+	"server2/embedding"
 	"server2/models"
-	"server2/openai"
+	"sync"
+	"time"
+)
+
+// startup embedding settings
+const (
+	embedBatchSize = 100 // texts per embedder call
+	embedWorkers   = 4   // concurrent embedder calls
+)
+
+// which vectors the catalog is currently scored with
+const (
+	ModeEmbedding = "embedding" // vectors from the configured embedder
+	ModeLexical   = "lexical"   // hashed bag-of-words fallback while the embedder is down
+)
+
+// retry schedule for the embedder after a failed startup
+var (
+	fallbackRetryDelay    = 5 * time.Second
+	fallbackMaxRetryDelay = 5 * time.Minute
 )
 
 // holds all food items with their embeddings
 type FoodStore struct {
-	foods     []models.FoodWithEmbedding // [[name, emm], [name, emm], [name, emm]......]
-	foodByID  map[string]*models.FoodWithEmbedding
-	dimension int
+	mu         sync.RWMutex
+	foods      []models.FoodWithEmbedding // [[name, emm], [name, emm], [name, emm]......]
+	foodByID   map[string]*models.FoodWithEmbedding
+	foodByName map[string]*models.FoodWithEmbedding // normalized names and aliases
+	bm25       *BM25Index
+	clusters   [][]string // food IDs grouped for cold-start cards
+	mode       string
+	dimension  int
+
+	dataPath          string
+	format            string // catalog format, "" detects it from the file extension
+	coldStartClusters int
+	embedder          embedding.Embedder
+	lexical           *embedding.HashEmbedder
+
+	progress progress
+
+	reloadMu sync.Mutex // serializes Load, Reload and the embedding retry
+	retrying bool
 }
 
 // creates a new food store and loads foods from json
-func NewFoodStore(dataPath string, client *openai.Client) (*FoodStore, error) {
-	data, err := os.ReadFile(dataPath)
+func NewFoodStore(dataPath string, embedder embedding.Embedder) (*FoodStore, error) {
+	store := OpenFoodStore(dataPath, embedder)
+	if err := store.Load(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// creates an empty food store, call Load to fill it
+func OpenFoodStore(dataPath string, embedder embedding.Embedder) *FoodStore {
+	return &FoodStore{
+		dataPath: dataPath,
+		embedder: embedder,
+		lexical:  embedding.NewHashEmbedder(embedding.DefaultHashDimension),
+
+		coldStartClusters: DefaultColdStartClusters,
+	}
+}
+
+// sets the catalog file format (see catalog.Formats), call before Load
+func (s *FoodStore) SetFormat(format string) {
+	s.format = format
+}
+
+// sets how many clusters the catalog is split into for cold-start cards,
+// 0 disables them. call before Load.
+func (s *FoodStore) SetColdStartClusters(k int) {
+	s.coldStartClusters = k
+}
+
+// reads foods from json and embeds them.
+// if the embedder fails, foods are served with lexical vectors and
+// embeddings are retried in the background.
+// a failed load is kept in Status and LoadError.
+func (s *FoodStore) Load() error {
+	if err := s.load(); err != nil {
+		s.progress.loadFailed(err)
+		return err
+	}
+	return nil
+}
+
+func (s *FoodStore) load() error {
+	foods, err := s.readFoods()
+	if err != nil {
+		return err
+	}
+
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+	if err := s.apply(foods, nil); err != nil {
+		return err
+	}
+
+	fmt.Printf("Loaded %d foods (%s mode)\n", len(foods), s.Mode())
+	return nil
+}
+
+// reads and validates the catalog file
+func (s *FoodStore) readFoods() ([]models.Food, error) {
+	foods, err := catalog.ReadFile(s.dataPath, s.format)
+	if err != nil {
+		return nil, err
+	}
+	if err := models.ValidateFoods(foods); err != nil {
+		return nil, fmt.Errorf("invalid foods file %s: %w", s.dataPath, err)
+	}
+	for _, w := range models.LintFoods(foods) {
+		fmt.Printf("Warning: %v\n", w)
+	}
+	return foods, nil
+}
+
+// embeds foods and swaps them in, see vectorsFor. callers hold reloadMu.
+func (s *FoodStore) apply(foods []models.Food, known map[string][]float64) error {
+	vectors, mode, err := s.vectorsFor(foods, known)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read foods file: %w", err)
+		return err
 	}
+	s.swapIn(foods, vectors, mode)
+	return nil
+}
 
-	var foods []models.Food
-	if err := json.Unmarshal(data, &foods); err != nil {
-		return nil, fmt.Errorf("failed to parse foods: %w", err)
+// vectors for a new version of the catalog. known holds vectors that can be
+// reused by text. when the embedder fails a catalog that is served with
+// embeddings is left alone and ErrEmbedderUnavailable is returned; before the
+// first embeddings the foods get lexical vectors instead.
+func (s *FoodStore) vectorsFor(foods []models.Food, known map[string][]float64) ([][]float64, string, error) {
+	embeddings, err := s.embedFoods(foods, known)
+	if err == nil {
+		return embeddings, ModeEmbedding, nil
 	}
+	if s.Mode() == ModeEmbedding {
+		return nil, "", fmt.Errorf("%w: %v", ErrEmbedderUnavailable, err)
+	}
+	fmt.Printf("Embedding provider unavailable (%v), using lexical fallback\n", err)
+	lexical, _ := s.lexical.GetEmbeddings(foodTexts(foods))
+	return lexical, ModeLexical, nil
+}
 
-	store := &FoodStore{
-		foods:     make([]models.FoodWithEmbedding, 0, len(foods)),
-		foodByID:  make(map[string]*models.FoodWithEmbedding),
-		dimension: client.GetEmbeddingDimension(),
+// swaps in foods with vectors from vectorsFor, retrying embeddings in the
+// background while they are lexical
+func (s *FoodStore) swapIn(foods []models.Food, vectors [][]float64, mode string) {
+	s.setFoods(foods, vectors, mode)
+	if mode == ModeLexical {
+		s.startRetry()
 	}
+}
 
-	fmt.Println("Generating embeddings for foods...")
+// embeds foods in memory with the text the store uses, without the cache
+func EmbedFoods(embedder embedding.Embedder, foods []models.Food) ([]models.FoodWithEmbedding, error) {
+	vecs, err := embedding.EmbedAll(embedder, foodTexts(foods))
+	if err != nil {
+		return nil, err
+	}
+	list := make([]models.FoodWithEmbedding, len(foods))
 	for i, food := range foods {
-		text := food.Name + ": " + food.Description
+		list[i] = models.FoodWithEmbedding{Food: food, Embedding: vecs[i]}
+	}
+	return list, nil
+}
+
+// text that gets embedded for each food
+func foodTexts(foods []models.Food) []string {
+	texts := make([]string, len(foods))
+	for i, food := range foods {
+		texts[i] = foodText(food)
+	}
+	return texts
+}
+
+func foodText(food models.Food) string {
+	return food.Name + ": " + food.Description
+}
+
+// embeds foods with the configured embedder, only calling it for foods
+// missing from known and the cache
+func (s *FoodStore) embedFoods(foods []models.Food, known map[string][]float64) ([][]float64, error) {
+	model := s.embedder.Model()
+	dimension, err := embedding.DimensionOf(s.embedder)
+	if err != nil {
+		s.progress.providerFailed(err)
+		return nil, err
+	}
+	cache := LoadEmbeddingCache(CachePath(s.dataPath))
+
+	s.progress.start(len(foods))
+
+	embeddings := make([][]float64, len(foods))
+	keys := make(map[string]bool, len(foods))
+	var missing []int
+	var texts []string
+
+	for i, text := range foodTexts(foods) {
+		key := CacheKey(model, dimension, text)
+		keys[key] = true
+
+		if vec, ok := known[text]; ok && len(vec) == dimension {
+			embeddings[i] = vec
+			cache.Put(key, vec)
+			s.progress.add(1)
+			continue
+		}
+		if vec, ok := cache.Get(key); ok && len(vec) == dimension {
+			embeddings[i] = vec
+			s.progress.add(1)
+			continue
+		}
+		missing = append(missing, i)
+		texts = append(texts, text)
+	}
 
-		embedding, err := client.GetEmbedding(text)
+	if len(texts) > 0 {
+		fmt.Printf("Generating embeddings for %d foods (%d cached)...\n", len(texts), len(foods)-len(texts))
+		vecs, err := embedConcurrently(s.embedder, texts, s.progress.add)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get embedding for %s: %w", food.Name, err)
+			s.progress.providerFailed(err)
+			return nil, fmt.Errorf("failed to get embeddings: %w", err)
 		}
+		for j, i := range missing {
+			embeddings[i] = vecs[j]
+			cache.Put(CacheKey(model, dimension, texts[j]), vecs[j])
+		}
+	} else {
+		fmt.Println("All food embeddings loaded from cache")
+	}
 
-		foodWithEmb := models.FoodWithEmbedding{
-			Food:      food,
-			Embedding: embedding,
+	cache.Retain(keys)
+	if err := cache.Save(); err != nil {
+		fmt.Printf("Warning: could not save embedding cache: %v\n", err)
+	}
+	s.progress.providerOK()
+	return embeddings, nil
+}
+
+// swaps in a new set of foods and vectors
+func (s *FoodStore) setFoods(foods []models.Food, vectors [][]float64, mode string) {
+	list := make([]models.FoodWithEmbedding, len(foods))
+	byID := make(map[string]*models.FoodWithEmbedding, len(foods))
+	byName := make(map[string]*models.FoodWithEmbedding, len(foods))
+	for i, food := range foods {
+		list[i] = models.FoodWithEmbedding{Food: food, Embedding: vectors[i]}
+		byID[food.ID] = &list[i]
+		if key := NormalizeName(food.Name); byName[key] == nil {
+			byName[key] = &list[i]
 		}
-		store.foods = append(store.foods, foodWithEmb)
-		store.foodByID[food.ID] = &store.foods[len(store.foods)-1]
+	}
+	// aliases never shadow a real name
+	for i := range list {
+		for _, alias := range list[i].Aliases {
+			if key := NormalizeName(alias); byName[key] == nil {
+				byName[key] = &list[i]
+			}
+		}
+	}
 
-		fmt.Printf("  [%d/%d] %s ✓\n", i+1, len(foods), food.Name)
+	dimension := 0
+	if len(vectors) > 0 {
+		dimension = len(vectors[0])
 	}
+	index := NewBM25Index(foods)
+	clusters := clusterFoods(list, s.coldStartClusters)
 
-	fmt.Printf("Loaded %d foods with embeddings\n", len(store.foods))
-	return store, nil
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.foods = list
+	s.foodByID = byID
+	s.foodByName = byName
+	s.bm25 = index
+	s.clusters = clusters
+	s.mode = mode
+	s.dimension = dimension
+	s.progress.markReady()
+}
+
+// starts the background embedding retry unless one is already running
+func (s *FoodStore) startRetry() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.retrying {
+		return
+	}
+	s.retrying = true
+	go s.retryEmbeddings()
+}
+
+// keeps trying the embedder with backoff, switching to embeddings once it works.
+// each attempt embeds the current catalog so reloads in between are picked up.
+func (s *FoodStore) retryEmbeddings() {
+	delay := fallbackRetryDelay
+	for {
+		time.Sleep(delay)
+
+		s.reloadMu.Lock()
+		if s.Mode() == ModeEmbedding {
+			// a reload got the embedder working in the meantime
+			s.stopRetry()
+			s.reloadMu.Unlock()
+			return
+		}
+		foods := catalogFoods(s.GetAll())
+		embeddings, err := s.embedFoods(foods, nil)
+		if err == nil {
+			s.setFoods(foods, embeddings, ModeEmbedding)
+			s.stopRetry()
+			s.reloadMu.Unlock()
+			fmt.Println("Embeddings ready, switched from lexical fallback")
+			return
+		}
+		s.reloadMu.Unlock()
+
+		delay *= 2
+		if delay > fallbackMaxRetryDelay {
+			delay = fallbackMaxRetryDelay
+		}
+		fmt.Printf("Embedding retry failed (%v), next attempt in %s\n", err, delay)
+	}
+}
+
+func (s *FoodStore) stopRetry() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.retrying = false
+}
+
+// strips vectors off a catalog snapshot
+func catalogFoods(list []models.FoodWithEmbedding) []models.Food {
+	foods := make([]models.Food, len(list))
+	for i, item := range list {
+		foods[i] = item.Food
+	}
+	return foods
+}
+
+// embeds texts in batches on a bounded worker pool, reporting progress per batch
+func embedConcurrently(embedder embedding.Embedder, texts []string, progress func(n int)) ([][]float64, error) {
+	result := make([][]float64, len(texts))
+
+	type batch struct{ start, end int }
+	batches := make(chan batch)
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		done     int
+		firstErr error
+	)
+
+	for w := 0; w < embedWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for b := range batches {
+				vecs, err := embedding.EmbedAll(embedder, texts[b.start:b.end])
+
+				mu.Lock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = err
+					}
+				} else {
+					copy(result[b.start:b.end], vecs)
+					done += b.end - b.start
+					progress(b.end - b.start)
+					fmt.Printf("  [%d/%d] embedded\n", done, len(texts))
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for start := 0; start < len(texts); start += embedBatchSize {
+		mu.Lock()
+		failed := firstErr != nil
+		mu.Unlock()
+		if failed {
+			break
+		}
+
+		end := start + embedBatchSize
+		if end > len(texts) {
+			end = len(texts)
+		}
+		batches <- batch{start, end}
+	}
+	close(batches)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return result, nil
+}
+
+// embeds free text into the vector space of a mode, so it can be compared
+// with the food vectors. pass the mode returned
+// by Snapshot with the foods the vector is compared to, the catalog may
+// switch modes in between.
+func (s *FoodStore) EmbedQueryIn(mode, text string) ([]float64, error) {
+	if mode == ModeLexical {
+		return s.lexical.GetEmbedding(text)
+	}
+	return s.embedder.GetEmbedding(text)
 }
 
 // GetAll returns all foods
 func (s *FoodStore) GetAll() []models.FoodWithEmbedding {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	return s.foods
 }
 
-// food by name
+// returns all foods together with the mode their vectors belong to
+func (s *FoodStore) Snapshot() ([]models.FoodWithEmbedding, string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.foods, s.mode
+}
+
+// keyword index over the current foods
+func (s *FoodStore) BM25() *BM25Index {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.bm25
+}
+
+// food IDs grouped into clusters spread over the vector space, nearest the
+// cluster center first. recomputed whenever the foods or vectors change.
+func (s *FoodStore) Clusters() [][]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.clusters
+}
+
+// food by name or alias, ignoring case and extra spaces
 func (s *FoodStore) GetByName(name string) *models.FoodWithEmbedding {
-	for i := range s.foods {
-		if s.foods[i].Name == name {
-			return &s.foods[i]
-		}
-	}
-	return nil
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.foodByName[NormalizeName(name)]
+}
+
+// food by ID
+func (s *FoodStore) GetByID(id string) *models.FoodWithEmbedding {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.foodByID[id]
+}
+
+// lowercases a name and collapses whitespace, used as the name index key
+func NormalizeName(name string) string {
+	return models.NormalizeName(name)
+}
+
+// returns the active scoring mode (embedding or lexical)
+func (s *FoodStore) Mode() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.mode
+}
+
+// returns the size of the vectors currently in use
+func (s *FoodStore) Dimension() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.dimension
 }
 
 // FoodWithEmbedding type alias for handlers
@@ -0,0 +1,164 @@
+package store
+
+import (
+	"errors"
+	"server2/embedding"
+	"server2/models"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// embedder that fails until it is switched on
+type flakyEmbedder struct {
+	inner *embedding.HashEmbedder
+	up    atomic.Bool
+}
+
+func (e *flakyEmbedder) GetEmbedding(text string) ([]float64, error) {
+	if !e.up.Load() {
+		return nil, errors.New("provider down")
+	}
+	return e.inner.GetEmbedding(text)
+}
+
+func (e *flakyEmbedder) Dimension() int {
+	return e.inner.Dimension()
+}
+
+func (e *flakyEmbedder) Model() string {
+	return "flaky"
+}
+
+func TestFoodStoreFallsBackToLexical(t *testing.T) {
+	fallbackRetryDelay = 10 * time.Millisecond
+	fallbackMaxRetryDelay = 10 * time.Millisecond
+
+	path := writeFoods(t, t.TempDir(), testFoods)
+	e := &flakyEmbedder{inner: embedding.NewHashEmbedder(16)}
+
+	store, err := NewFoodStore(path, e)
+	if err != nil {
+		t.Fatalf("Store should start even when embeddings fail, got %v", err)
+	}
+	if store.Mode() != ModeLexical {
+		t.Errorf("Mode() = %q, want %q", store.Mode(), ModeLexical)
+	}
+	if store.Dimension() != embedding.DefaultHashDimension {
+		t.Errorf("Lexical vectors should have %d dims, got %d", embedding.DefaultHashDimension, store.Dimension())
+	}
+	if len(store.GetAll()) != 2 {
+		t.Fatalf("Expected 2 foods, got %d", len(store.GetAll()))
+	}
+
+	e.up.Store(true)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for store.Mode() != ModeEmbedding {
+		if time.Now().After(deadline) {
+			t.Fatal("Store never switched to embeddings")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if store.Dimension() != 16 {
+		t.Errorf("Expected embedder dimension 16 after switch, got %d", store.Dimension())
+	}
+}
+
+func TestFailedEmbeddingKeepsCatalog(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFoods(t, dir, testFoods)
+	e := &flakyEmbedder{inner: embedding.NewHashEmbedder(16)}
+	e.up.Store(true)
+	store, err := NewFoodStore(path, e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.up.Store(false)
+
+	if _, err := store.AddFood(models.Food{Name: "Tacos", Description: "Corn tortillas"}); !errors.Is(err, ErrEmbedderUnavailable) {
+		t.Errorf("AddFood() error = %v, want ErrEmbedderUnavailable", err)
+	}
+	if len(readCatalog(t, path)) != 2 {
+		t.Error("A food that cannot be embedded should not be saved")
+	}
+
+	writeFoods(t, dir, `[
+		{"id": "1", "name": "Butter Chicken", "description": "Creamy curry"},
+		{"id": "3", "name": "Ramen", "description": "Noodle soup"}
+	]`)
+	if _, err := store.Reload(); !errors.Is(err, ErrEmbedderUnavailable) {
+		t.Errorf("Reload() error = %v, want ErrEmbedderUnavailable", err)
+	}
+	if store.Mode() != ModeEmbedding || store.Dimension() != 16 {
+		t.Errorf("Catalog should keep its embeddings, got %s mode with %d dims", store.Mode(), store.Dimension())
+	}
+	if len(store.GetAll()) != 2 || store.GetByName("Sushi") == nil || store.GetByName("Ramen") != nil {
+		t.Error("Failed reload should keep the current foods")
+	}
+}
+
+func TestSessionStoreUsesCatalogDimension(t *testing.T) {
+	path := writeFoods(t, t.TempDir(), testFoods)
+	foods, err := NewFoodStore(path, embedding.NewHashEmbedder(24))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sessions := NewSessionStore(NewCatalogs(foods))
+	id, err := sessions.Create(SessionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := sessions.Get(id)
+	if len(session.GetIntent()) != 24 {
+		t.Errorf("Intent should match catalog dimension 24, got %d", len(session.GetIntent()))
+	}
+	if session.GetIntentSpace() != ModeEmbedding {
+		t.Errorf("IntentSpace = %q, want %q", session.GetIntentSpace(), ModeEmbedding)
+	}
+}
+
+func TestFoodStoreLookups(t *testing.T) {
+	path := writeFoods(t, t.TempDir(), `[
+		{"id": "1", "name": "Butter Chicken", "aliases": ["Murgh Makhani"], "description": "Creamy curry"},
+		{"id": "2", "name": "Murgh Makhani", "description": "Same dish, different menu"},
+		{"id": "3", "name": "Masala Chai", "aliases": ["Chai"], "description": "Spiced tea"}
+	]`)
+	store, err := NewFoodStore(path, embedding.NewHashEmbedder(16))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		lookup string
+		wantID string
+	}{
+		{"exact name", "Butter Chicken", "1"},
+		{"case and spaces", "  butter   CHICKEN ", "1"},
+		{"alias", "chai", "3"},
+		{"name wins over alias", "Murgh Makhani", "2"},
+		{"unknown", "Tacos", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			food := store.GetByName(tt.lookup)
+			gotID := ""
+			if food != nil {
+				gotID = food.ID
+			}
+			if gotID != tt.wantID {
+				t.Errorf("GetByName(%q) = %q, want %q", tt.lookup, gotID, tt.wantID)
+			}
+		})
+	}
+
+	if food := store.GetByID("3"); food == nil || food.Name != "Masala Chai" {
+		t.Errorf("GetByID(3) = %v", food)
+	}
+	if store.GetByID("99") != nil {
+		t.Error("GetByID should return nil for unknown IDs")
+	}
+}
@@ -0,0 +1,136 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"server2/models"
+	"time"
+)
+
+// what changed between two versions of the catalog
+type ReloadStats struct {
+	Added     int `json:"added"`
+	Changed   int `json:"changed"`
+	Removed   int `json:"removed"`
+	Unchanged int `json:"unchanged"`
+	Reembed   int `json:"reembed"` // added or changed foods whose text needs new vectors
+}
+
+func (r ReloadStats) String() string {
+	return fmt.Sprintf("%d added, %d changed, %d removed, %d unchanged, %d to embed",
+		r.Added, r.Changed, r.Removed, r.Unchanged, r.Reembed)
+}
+
+// re-reads the catalog file and swaps it in atomically.
+// vectors of foods whose text did not change are reused, so only new or
+// edited foods hit the embedder. on any read or validation error, or when
+// those foods cannot be embedded, the current catalog stays in place.
+func (s *FoodStore) Reload() (ReloadStats, error) {
+	foods, err := s.readFoods()
+	if err != nil {
+		return ReloadStats{}, err
+	}
+
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	current, mode := s.Snapshot()
+	stats := diffCatalog(current, foods)
+
+	// lexical vectors are never reused, they get replaced once the embedder answers
+	var known map[string][]float64
+	if mode == ModeEmbedding {
+		known = make(map[string][]float64, len(current))
+		for _, item := range current {
+			known[foodText(item.Food)] = item.Embedding
+		}
+	}
+
+	if err := s.apply(foods, known); err != nil {
+		return ReloadStats{}, err
+	}
+	fmt.Printf("Reloaded %s: %s (%s mode)\n", s.dataPath, stats, s.Mode())
+	return stats, nil
+}
+
+// compares the current catalog with a new one by food ID
+func diffCatalog(current []models.FoodWithEmbedding, next []models.Food) ReloadStats {
+	old := make(map[string]models.Food, len(current))
+	for _, item := range current {
+		old[item.ID] = item.Food
+	}
+
+	var stats ReloadStats
+	seen := make(map[string]bool, len(next))
+	for _, food := range next {
+		seen[food.ID] = true
+		prev, ok := old[food.ID]
+		switch {
+		case !ok:
+			stats.Added++
+			stats.Reembed++
+		case sameFood(prev, food):
+			stats.Unchanged++
+		default:
+			stats.Changed++
+			if foodText(prev) != foodText(food) {
+				stats.Reembed++
+			}
+		}
+	}
+	for id := range old {
+		if !seen[id] {
+			stats.Removed++
+		}
+	}
+	return stats
+}
+
+// true if both foods encode to the same json, so an empty list and a
+// missing one (dropped by omitempty when the catalog is saved) are equal
+func sameFood(a, b models.Food) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}
+
+// polls the catalog file and reloads it whenever it changes.
+// runs until stop is closed; a zero interval disables watching.
+func (s *FoodStore) Watch(interval time.Duration, stop <-chan struct{}) {
+	if interval <= 0 {
+		return
+	}
+
+	last, _ := fileVersion(s.dataPath)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		version, err := fileVersion(s.dataPath)
+		if err != nil || version == last {
+			continue
+		}
+		last = version
+
+		if _, err := s.Reload(); err != nil {
+			fmt.Printf("Reload failed, keeping current catalog: %v\n", err)
+		}
+	}
+}
+
+// mtime and size of a file, enough to notice edits and atomic replaces
+func fileVersion(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d-%d", info.ModTime().UnixNano(), info.Size()), nil
+}
@@ -0,0 +1,130 @@
+package store
+
+import (
+	"os"
+	"server2/embedding"
+	"testing"
+	"time"
+)
+
+func TestReloadOnlyEmbedsChangedFoods(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFoods(t, dir, `[
+		{"id": "1", "name": "Butter Chicken", "description": "Creamy curry"},
+		{"id": "2", "name": "Sushi", "description": "Rice and raw fish"},
+		{"id": "3", "name": "Tacos", "description": "Corn tortillas"}
+	]`)
+
+	e := &countingEmbedder{inner: embedding.NewHashEmbedder(32), model: "m1"}
+	store, err := NewFoodStore(path, e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := store.GetByID("1")
+
+	// vectors must come from the live catalog, not just the cache file
+	os.Remove(CachePath(path))
+	writeFoods(t, dir, `[
+		{"id": "1", "name": "Butter Chicken", "description": "Creamy curry", "spice_level": 2},
+		{"id": "2", "name": "Sushi", "description": "Vinegared rice and raw fish"},
+		{"id": "4", "name": "Ramen", "description": "Noodle soup"}
+	]`)
+
+	e.calls = 0
+	stats, err := store.Reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := ReloadStats{Added: 1, Changed: 2, Removed: 1, Unchanged: 0, Reembed: 2}
+	if stats != want {
+		t.Errorf("Reload() stats = %+v, want %+v", stats, want)
+	}
+	if e.calls != 2 {
+		t.Errorf("Only the edited and new food should be embedded, embedded %d", e.calls)
+	}
+	if store.GetByID("3") != nil || store.GetByName("Tacos") != nil {
+		t.Error("Removed food should be gone from the indexes")
+	}
+	if food := store.GetByID("1"); food == nil || food.SpiceLevel != 2 {
+		t.Errorf("GetByID(1) should return the edited food, got %v", food)
+	}
+	if store.GetByName("ramen") == nil {
+		t.Error("New food should be indexed by name")
+	}
+
+	// foods handed out before the reload stay readable
+	if before.Name != "Butter Chicken" || before.SpiceLevel != 0 {
+		t.Errorf("Old snapshot was modified: %+v", before.Food)
+	}
+}
+
+func TestReloadIgnoresEmptyLists(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFoods(t, dir, `[
+		{"id": "1", "name": "Butter Chicken", "description": "Creamy curry", "allergens": [], "tags": []},
+		{"id": "2", "name": "Sushi", "description": "Rice and raw fish", "allergens": ["fish"]}
+	]`)
+	store, err := NewFoodStore(path, embedding.NewHashEmbedder(16))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// what saveCatalog writes: omitempty drops the empty lists
+	writeFoods(t, dir, `[
+		{"id": "1", "name": "Butter Chicken", "description": "Creamy curry"},
+		{"id": "2", "name": "Sushi", "description": "Rice and raw fish", "allergens": ["fish"]}
+	]`)
+	stats, err := store.Reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (ReloadStats{Unchanged: 2}); stats != want {
+		t.Errorf("Reload() stats = %+v, want %+v", stats, want)
+	}
+}
+
+func TestReloadKeepsCatalogOnInvalidFile(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFoods(t, dir, testFoods)
+	store, err := NewFoodStore(path, embedding.NewHashEmbedder(16))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeFoods(t, dir, `[{"id": "1", "name": ""}]`)
+	if _, err := store.Reload(); err == nil {
+		t.Fatal("Reload should reject an invalid catalog")
+	}
+	if len(store.GetAll()) != 2 || store.GetByName("Sushi") == nil {
+		t.Error("Invalid reload should keep the current catalog")
+	}
+}
+
+func TestWatchReloadsOnChange(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFoods(t, dir, testFoods)
+	store, err := NewFoodStore(path, embedding.NewHashEmbedder(16))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go store.Watch(5*time.Millisecond, stop)
+
+	time.Sleep(20 * time.Millisecond)
+	writeFoods(t, dir, `[
+		{"id": "1", "name": "Butter Chicken", "description": "Creamy curry"},
+		{"id": "2", "name": "Sushi", "description": "Rice and raw fish"},
+		{"id": "3", "name": "Tacos", "description": "Corn tortillas"}
+	]`)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for store.GetByID("3") == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("Watch never picked up the edited file")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
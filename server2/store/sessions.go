@@ -1,6 +1,7 @@
 package store
 
 import (
+	"math/rand"
 	"server2/models"
 	"sync"
 
@@ -9,28 +10,62 @@ import (
 
 // manages all active sessions
 type SessionStore struct {
-	sessions  map[string]*models.Session
-	mu        sync.RWMutex
-	dimension int
+	sessions map[string]*models.Session
+	mu       sync.RWMutex
+	catalogs *Catalogs
 }
 
-//  creates a new session store
-func NewSessionStore(dimension int) *SessionStore {
+//  creates a new session store, intents are sized to each catalog's vectors
+func NewSessionStore(catalogs *Catalogs) *SessionStore {
 	return &SessionStore{
-		sessions:  make(map[string]*models.Session),
-		dimension: dimension,
+		sessions: make(map[string]*models.Session),
+		catalogs: catalogs,
 	}
 }
 
-// creates a new session and returns its ID
-func (s *SessionStore) Create() string {
+// settings chosen when a session is created
+type SessionOptions struct {
+	Catalog     string // catalog ID, "" for the default catalog
+	Constraints models.Constraints
+	Query       string // free text to seed the intent with, see Recommender.SeedIntent
+	Seed        *int64 // seeds random exploration, random when nil
+	Strategy    string // recommendation strategy, "" for the deployment default
+}
+
+// creates a new session on a catalog and returns its ID
+func (s *SessionStore) Create(opts SessionOptions) (string, error) {
+	foodStore := s.catalogs.Get(opts.Catalog)
+	if foodStore == nil {
+		return "", ErrCatalogNotFound
+	}
+	if opts.Catalog == "" {
+		opts.Catalog = DefaultCatalog
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	id := uuid.New().String()
-	session := models.NewSession(id, s.dimension)
+	session := models.NewSession(id, foodStore.Dimension())
+	session.IntentSpace = foodStore.Mode()
+	session.Catalog = opts.Catalog
+	session.Constraints = opts.Constraints
+	session.Query = opts.Query
+	session.Strategy = opts.Strategy
+	if opts.Seed != nil {
+		session.Seed = *opts.Seed
+	} else {
+		session.Seed = rand.Int63()
+	}
 	s.sessions[id] = session
-	return id
+	return id, nil
+}
+
+// removes a session
+func (s *SessionStore) Delete(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, id)
 }
 
 // returns a session by ID
@@ -40,3 +75,16 @@ func (s *SessionStore) Get(id string) *models.Session {
 	return s.sessions[id]
 }
 
+
+// returns how many sessions exist and how many of them are completed
+func (s *SessionStore) Counts() (total, completed int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, session := range s.sessions {
+		if session.IsCompleted() {
+			completed++
+		}
+	}
+	return len(s.sessions), completed
+}
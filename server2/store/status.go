@@ -0,0 +1,116 @@
+package store
+
+import (
+	"fmt"
+	"sync"
+)
+
+// provider states reported in LoadStatus
+const (
+	ProviderPending     = "pending"
+	ProviderOK          = "ok"
+	ProviderUnavailable = "unavailable"
+)
+
+// catalog loading progress, reported by /healthz and /readyz
+type LoadStatus struct {
+	Ready         bool   `json:"ready"`
+	Mode          string `json:"mode,omitempty"`
+	Foods         int    `json:"foods"`
+	Embedded      int    `json:"embedded"` // foods with provider embeddings (cached or fresh)
+	Provider      string `json:"provider"`
+	ProviderError string `json:"provider_error,omitempty"`
+	LoadError     string `json:"load_error,omitempty"` // why Load failed, the catalog never becomes ready
+}
+
+// tracks loading progress of a FoodStore
+type progress struct {
+	mu          sync.Mutex
+	ready       bool
+	total       int
+	embedded    int
+	provider    string
+	providerErr string
+	loadErr     string
+}
+
+// resets the counters for a new embedding pass
+func (p *progress) start(total int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.total = total
+	p.embedded = 0
+}
+
+// counts n more foods as embedded
+func (p *progress) add(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.embedded += n
+}
+
+func (p *progress) providerOK() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.provider = ProviderOK
+	p.providerErr = ""
+}
+
+func (p *progress) providerFailed(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.provider = ProviderUnavailable
+	p.providerErr = err.Error()
+}
+
+func (p *progress) loadFailed(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.loadErr = err.Error()
+}
+
+func (p *progress) markReady() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.ready = true
+}
+
+// returns the current loading status
+func (s *FoodStore) Status() LoadStatus {
+	mode := s.Mode()
+
+	p := &s.progress
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	status := LoadStatus{
+		Ready:         p.ready,
+		Mode:          mode,
+		Foods:         p.total,
+		Embedded:      p.embedded,
+		Provider:      p.provider,
+		ProviderError: p.providerErr,
+		LoadError:     p.loadErr,
+	}
+	if status.Provider == "" {
+		status.Provider = ProviderPending
+	}
+	return status
+}
+
+// ErrLoadFailed wrapping the reason if Load failed, nil while loading or loaded
+func (s *FoodStore) LoadError() error {
+	s.progress.mu.Lock()
+	defer s.progress.mu.Unlock()
+	if s.progress.loadErr == "" {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrLoadFailed, s.progress.loadErr)
+}
+
+// true once foods can be served (with embeddings or the lexical fallback)
+func (s *FoodStore) Ready() bool {
+	s.progress.mu.Lock()
+	defer s.progress.mu.Unlock()
+	return s.progress.ready
+}
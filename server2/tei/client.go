@@ -0,0 +1,190 @@
+package tei
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"server2/embedding"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const DefaultBaseURL = "http://localhost:8080"
+const DefaultTimeout = 60 * time.Second
+const DefaultBatchSize = 32 // TEI's default --max-client-batch-size
+
+// client settings, zero values fall back to the defaults above
+type Config struct {
+	BaseURL   string
+	APIKey    string // optional bearer token
+	Model     string // only used to name the vector space for caching
+	BatchSize int
+	Timeout   time.Duration
+}
+
+// embedding client for Hugging Face Text-Embeddings-Inference /embed
+type Client struct {
+	config     Config
+	httpClient *http.Client
+
+	dimension embedding.LearnedDimension
+}
+
+// creates a new TEI client from env vars
+func NewClient() *Client {
+	return NewClientWithConfig(ConfigFromEnv())
+}
+
+// reads client settings from TEI_* env vars
+func ConfigFromEnv() Config {
+	cfg := Config{
+		BaseURL: os.Getenv("TEI_BASE_URL"),
+		APIKey:  os.Getenv("TEI_API_KEY"),
+		Model:   os.Getenv("TEI_MODEL"),
+	}
+	if n, err := strconv.Atoi(os.Getenv("TEI_BATCH_SIZE")); err == nil {
+		cfg.BatchSize = n
+	}
+	if d, err := time.ParseDuration(os.Getenv("TEI_TIMEOUT")); err == nil {
+		cfg.Timeout = d
+	}
+	return cfg
+}
+
+// creates a new TEI client with explicit settings
+func NewClientWithConfig(cfg Config) *Client {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DefaultBaseURL
+	}
+	if cfg.Model == "" {
+		cfg.Model = cfg.BaseURL
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultBatchSize
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+
+	return &Client{
+		config:     cfg,
+		httpClient: &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// request body for /embed
+type EmbedRequest struct {
+	Inputs    []string `json:"inputs"`
+	Normalize bool     `json:"normalize"`
+	Truncate  bool     `json:"truncate"`
+}
+
+// error body returned by TEI
+type errorResponse struct {
+	Error     string `json:"error"`
+	ErrorType string `json:"error_type"`
+}
+
+// generates an embedding for the given text
+func (c *Client) GetEmbedding(text string) ([]float64, error) {
+	embeddings, err := c.embed(context.Background(), []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return embeddings[0], nil
+}
+
+// generates embeddings for many texts in batches the server accepts
+func (c *Client) GetEmbeddings(texts []string) ([][]float64, error) {
+	return c.GetEmbeddingsContext(context.Background(), texts)
+}
+
+// batched GetEmbeddings, giving up when ctx is done
+func (c *Client) GetEmbeddingsContext(ctx context.Context, texts []string) ([][]float64, error) {
+	result := make([][]float64, 0, len(texts))
+	for start := 0; start < len(texts); start += c.config.BatchSize {
+		end := start + c.config.BatchSize
+		if end > len(texts) {
+			end = len(texts)
+		}
+
+		embeddings, err := c.embed(ctx, texts[start:end])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, embeddings...)
+	}
+	return result, nil
+}
+
+// sends one /embed request
+func (c *Client) embed(ctx context.Context, texts []string) ([][]float64, error) {
+	jsonBody, err := json.Marshal(EmbedRequest{Inputs: texts, Normalize: true, Truncate: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.config.BaseURL+"/embed", bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.config.APIKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp errorResponse
+		if json.Unmarshal(body, &errResp) == nil && errResp.Error != "" {
+			return nil, fmt.Errorf("tei error %d (%s): %s", resp.StatusCode, errResp.ErrorType, errResp.Error)
+		}
+		return nil, fmt.Errorf("tei error %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var embeddings [][]float64
+	if err := json.Unmarshal(body, &embeddings); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if len(embeddings) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(embeddings))
+	}
+
+	for i := range embeddings {
+		if err := c.dimension.Learn(len(embeddings[i])); err != nil {
+			return nil, err
+		}
+	}
+	return embeddings, nil
+}
+
+// returns the name of the vector space
+func (c *Client) Model() string {
+	return "tei/" + c.config.Model
+}
+
+// returns the embedding dimension learned so far, 0 before the first response
+func (c *Client) Dimension() int {
+	return c.dimension.Get()
+}
+
+// returns the embedding dimension, probing the server once if unknown
+func (c *Client) ProbeDimension() (int, error) {
+	return c.dimension.Probe(c)
+}
@@ -0,0 +1,93 @@
+package tei
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fake TEI server that embeds each input by its length
+func newFakeServer(t *testing.T, maxBatch int, requests *int) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/embed" {
+			http.NotFound(w, r)
+			return
+		}
+		*requests++
+
+		var req EmbedRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "bad json", http.StatusBadRequest)
+			return
+		}
+		if len(req.Inputs) > maxBatch {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"error":"batch size too large","error_type":"Validation"}`))
+			return
+		}
+
+		out := make([][]float64, len(req.Inputs))
+		for i, in := range req.Inputs {
+			out[i] = []float64{float64(len(in)), 0, 1}
+		}
+		json.NewEncoder(w).Encode(out)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGetEmbeddingsBatches(t *testing.T) {
+	var requests int
+	server := newFakeServer(t, 2, &requests)
+	c := NewClientWithConfig(Config{BaseURL: server.URL, BatchSize: 2})
+
+	texts := []string{"a", "bb", "ccc", "dddd", "eeeee"}
+	vecs, err := c.GetEmbeddings(texts)
+	if err != nil {
+		t.Fatalf("GetEmbeddings() error = %v", err)
+	}
+	if requests != 3 {
+		t.Errorf("Expected 3 requests for 5 texts in batches of 2, got %d", requests)
+	}
+	for i, vec := range vecs {
+		if vec[0] != float64(len(texts[i])) {
+			t.Errorf("Embedding %d out of order: %v", i, vec)
+		}
+	}
+	if c.Dimension() != 3 {
+		t.Errorf("Dimension() = %d, want 3", c.Dimension())
+	}
+}
+
+func TestValidationError(t *testing.T) {
+	var requests int
+	server := newFakeServer(t, 1, &requests)
+	c := NewClientWithConfig(Config{BaseURL: server.URL, BatchSize: 4})
+
+	_, err := c.GetEmbeddings([]string{"a", "b"})
+	if err == nil || !strings.Contains(err.Error(), "Validation") {
+		t.Errorf("Expected validation error, got %v", err)
+	}
+}
+
+func TestAuthorizationHeader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`[[1,2]]`))
+	}))
+	defer server.Close()
+
+	c := NewClientWithConfig(Config{BaseURL: server.URL, APIKey: "secret", Model: "bge-small"})
+	if _, err := c.GetEmbedding("pizza"); err != nil {
+		t.Fatalf("GetEmbedding() error = %v", err)
+	}
+	if c.Model() != "tei/bge-small" {
+		t.Errorf("Model() = %q", c.Model())
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"server2/catalog"
+	"server2/config"
+	"server2/embedding"
+	"server2/engine"
+	"server2/models"
+	"server2/store"
+	"strings"
+)
+
+// validate [-offline] [-threshold 0.95] [-strict] [-format csv] [path]
+// checks a catalog file and exits non-zero when it would not load
+func runValidate(cfg *config.Config, args []string) int {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	offline := fs.Bool("offline", false, "use the offline embedder for the near-duplicate check")
+	threshold := fs.Float64("threshold", cfg.DuplicateThreshold, "cosine similarity at which foods count as near-duplicates")
+	strict := fs.Bool("strict", false, "also fail on warnings")
+	format := fs.String("format", cfg.DataFormat, "catalog format ("+strings.Join(catalog.Formats(), ", ")+"), default from the file extension")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: server validate [flags] [foods.json]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	path := cfg.DataPath
+	if fs.NArg() > 0 {
+		path = fs.Arg(0)
+	}
+
+	var embedder embedding.Embedder = embedding.NewHashEmbedder(cfg.OfflineDimension)
+	if !*offline {
+		var err error
+		if embedder, err = newEmbedder(cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create embedder: %v\n", err)
+			return 1
+		}
+	}
+
+	// hard errors stop the load, report them all and bail.
+	// the check only reads: no embeddings cache, no background retries.
+	foods, err := catalog.ReadFile(path, *format)
+	if err == nil {
+		err = models.ValidateFoods(foods)
+	}
+	if err != nil {
+		var invalid models.ValidationErrors
+		if errors.As(err, &invalid) {
+			fmt.Printf("\n%s: %d error(s)\n", path, len(invalid))
+			for _, fe := range invalid {
+				fmt.Printf("  error    %v\n", fe)
+			}
+		} else {
+			fmt.Printf("\n%s: %v\n", path, err)
+		}
+		return 1
+	}
+
+	mode := store.ModeEmbedding
+	embedded, err := store.EmbedFoods(embedder, foods)
+	if err != nil {
+		fmt.Printf("Warning: embedding provider unavailable (%v), checking duplicates with lexical vectors\n", err)
+		mode = store.ModeLexical
+		if embedded, err = store.EmbedFoods(embedding.NewHashEmbedder(embedding.DefaultHashDimension), foods); err != nil {
+			fmt.Printf("\n%s: %v\n", path, err)
+			return 1
+		}
+	}
+
+	warnings := models.LintFoods(foods)
+	duplicates := engine.NearDuplicates(embedded, *threshold)
+
+	fmt.Printf("\n%s: %d foods, 0 errors, %d warning(s)\n", path, len(foods), len(warnings)+len(duplicates))
+	for _, w := range warnings {
+		fmt.Printf("  warning  %v\n", w)
+	}
+	for _, d := range duplicates {
+		fmt.Printf("  warning  foods %q (%s) and %q (%s) look like duplicates (%.3f similar, %s vectors)\n",
+			d.A.ID, d.A.Name, d.B.ID, d.B.Name, d.Score, mode)
+	}
+
+	if *strict && len(warnings)+len(duplicates) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// logs near-duplicate dishes once the catalog is loaded
+func reportDuplicates(foodStore *store.FoodStore, threshold float64) {
+	for _, d := range engine.NearDuplicates(foodStore.GetAll(), threshold) {
+		fmt.Printf("Warning: foods %q (%s) and %q (%s) look like duplicates (%.3f similar)\n",
+			d.A.ID, d.A.Name, d.B.ID, d.B.Name, d.Score)
+	}
+}